@@ -0,0 +1,174 @@
+package restc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// AnalysisInputs are files read by the analysis: selected project files, go files of packages indexed
+// by the type resolver keyed by package directory, files read to look up package names of imports keyed
+// by package directory and content hashes of all of them. Vendor reports whether packages were located
+// in the vendor directory
+type AnalysisInputs struct {
+	ProjectFiles   []string            `json:"projectFiles"`
+	Packages       map[string][]string `json:"packages"`
+	PackageClauses map[string]string   `json:"packageClauses"`
+	Vendor         bool                `json:"vendor"`
+	Hashes         map[string]string   `json:"hashes"`
+}
+
+// PackageDirs returns sorted directories of packages indexed by the type resolver or with looked up names
+func (i AnalysisInputs) PackageDirs() []string {
+	dirs := make([]string, 0, len(i.Packages)+len(i.PackageClauses))
+	for dir := range i.Packages {
+		dirs = append(dirs, dir)
+	}
+	for dir := range i.PackageClauses {
+		if _, ok := i.Packages[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// AnalysisCache keeps results of the AST analysis on disk between runs, a result is stored per module,
+// file filter and restc executable and it is reused while the module mode, the project files, package files
+// and contents of all of them and go.mod are unchanged
+type AnalysisCache struct {
+	dir string
+}
+
+func NewAnalysisCache(dir string) *AnalysisCache {
+	return &AnalysisCache{dir: dir}
+}
+
+type analysisCacheEntry struct {
+	Inputs      AnalysisInputs `json:"inputs"`
+	Definitions Definitions    `json:"definitions"`
+	Diagnostics []Diagnostic   `json:"diagnostics"`
+}
+
+// Load returns cached definitions, diagnostics and inputs of the module analysis if the inputs are unchanged
+func (c *AnalysisCache) Load(module *Module, filter FileFilter) (Definitions, []Diagnostic, AnalysisInputs, bool) {
+	data, err := os.ReadFile(c.path(module, filter))
+	if err != nil {
+		return Definitions{}, nil, AnalysisInputs{}, false
+	}
+
+	var entry analysisCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Definitions.Version != DefinitionsVersion {
+		return Definitions{}, nil, AnalysisInputs{}, false
+	}
+
+	// vendor directory is enabled or disabled by GOFLAGS, packages are located elsewhere then
+	if entry.Inputs.Vendor != module.vendor {
+		return Definitions{}, nil, AnalysisInputs{}, false
+	}
+
+	if files, _ := ProjectFiles(module.Root, filter); !slices.Equal(files, entry.Inputs.ProjectFiles) {
+		return Definitions{}, nil, AnalysisInputs{}, false
+	}
+
+	files := append(slices.Clone(entry.Inputs.ProjectFiles), filepath.Join(module.Root, "go.mod"))
+	for dir, packageFiles := range entry.Inputs.Packages {
+		if current, err := PackageFiles(dir); err != nil || !slices.Equal(current, packageFiles) {
+			return Definitions{}, nil, AnalysisInputs{}, false
+		}
+		files = append(files, packageFiles...)
+	}
+
+	for dir, file := range entry.Inputs.PackageClauses {
+		if current, err := packageClauseFile(dir); err != nil || current != file {
+			return Definitions{}, nil, AnalysisInputs{}, false
+		}
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	for _, file := range files {
+		hash, ok := entry.Inputs.Hashes[file]
+		if !ok {
+			return Definitions{}, nil, AnalysisInputs{}, false
+		}
+
+		if current, err := hashFile(file); err != nil || current != hash {
+			return Definitions{}, nil, AnalysisInputs{}, false
+		}
+	}
+
+	return entry.Definitions, entry.Diagnostics, entry.Inputs, true
+}
+
+// Store writes analysis result, go.mod hash is added to inputs
+func (c *AnalysisCache) Store(module *Module, filter FileFilter, inputs AnalysisInputs, definitions Definitions, diagnostics []Diagnostic) error {
+	goMod := filepath.Join(module.Root, "go.mod")
+	hash, err := hashFile(goMod)
+	if err != nil {
+		return err
+	}
+	inputs.Hashes[goMod] = hash
+
+	data, err := json.Marshal(analysisCacheEntry{Inputs: inputs, Definitions: definitions, Diagnostics: diagnostics})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("cannot create cache directory: %w", err)
+	}
+
+	// concurrent runs must not read partially written entry
+	tmp, err := os.CreateTemp(c.dir, "analysis-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path(module, filter))
+}
+
+// path returns entry file name derived from the module, filter patterns and restc executable,
+// so results of another restc build are not reused
+func (c *AnalysisCache) path(module *Module, filter FileFilter) string {
+	h := sha256.New()
+
+	fmt.Fprintln(h, module.Root, module.Path, DefinitionsVersion)
+	for _, re := range filter.Include {
+		fmt.Fprintln(h, "include", re.String())
+	}
+	for _, re := range filter.Exclude {
+		fmt.Fprintln(h, "exclude", re.String())
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		if info, err := os.Stat(executable); err == nil {
+			fmt.Fprintln(h, executable, info.Size(), strconv.FormatInt(info.ModTime().UnixNano(), 10))
+		}
+	}
+
+	return filepath.Join(c.dir, "analysis-"+hex.EncodeToString(h.Sum(nil))[:16]+".json")
+}
+
+func hashFile(filename string) (string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return hashContent(content), nil
+}
@@ -0,0 +1,195 @@
+package restc
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAnalysisCache(t *testing.T) {
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"dto/dto.go": `package dto
+
+type Item struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+`,
+		// package names differ from import paths, they are looked up in package clauses
+		"lib/go-util/util.go":     "package util\n",
+		"lib/go-labels/labels.go": "package labels\n\ntype Label string\n",
+		"api/api.go": `package api
+
+import (
+	"context"
+
+	"example.com/app/dto"
+	"example.com/app/lib/go-util"
+	"example.com/app/lib/go-labels"
+)
+
+// @Controller /api
+type ItemController struct{}
+
+// @Resource POST /items
+func (c *ItemController) CreateItem(ctx context.Context, body dto.Item, label labels.Label) error {
+	return nil
+}
+`,
+	}
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, root string)
+		filter []string
+		hit    bool
+	}{
+		{name: "unchanged", hit: true},
+		{
+			name: "rewritten with the same content",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "api/api.go"), files["api/api.go"])
+			},
+			hit: true,
+		},
+		{
+			name: "project file changed",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "api/api.go"), files["api/api.go"]+"\n// Comment\n")
+			},
+		},
+		{
+			name: "project file added",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "api/other.go"), "package api\n")
+			},
+		},
+		{
+			name: "project file removed",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "api/other.go"), "package api\n")
+				if err := os.Remove(filepath.Join(root, "api/api.go")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "go.mod changed",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n\ngo 1.23\n")
+			},
+		},
+		{
+			name: "dependency file changed",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "dto/dto.go"), files["dto/dto.go"]+"\ntype Label string\n")
+			},
+		},
+		{
+			name: "dependency file added",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "dto/label.go"), "package dto\n\ntype Label string\n")
+			},
+		},
+		{
+			name: "dependency test file added",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "dto/dto_test.go"), "package dto\n")
+			},
+			hit: true,
+		},
+		{
+			name: "package clause of looked up import changed",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "lib/go-util/util.go"), "package labels\n")
+			},
+		},
+		{
+			name: "file of looked up import added",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "lib/go-util/a.go"), "package util\n")
+			},
+		},
+		{
+			name: "other file of looked up import added",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "lib/go-util/z.go"), "package util\n")
+			},
+			hit: true,
+		},
+		{
+			name: "vendor directory added",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "vendor/modules.txt"), "")
+			},
+		},
+		{
+			name: "vendor directory ignored with GOFLAGS",
+			change: func(t *testing.T, root string) {
+				writeTestFile(t, filepath.Join(root, "vendor/modules.txt"), "")
+				t.Setenv("GOFLAGS", "-mod=mod")
+			},
+			hit: true,
+		},
+		{name: "another filter", filter: []string{`^api/`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOFLAGS", "")
+
+			module := testModule(t, files)
+			filter, _ := NewFileFilter([]string{`^api/`}, []string{`_test\.go$`})
+			cache := NewAnalysisCache(filepath.Join(t.TempDir(), "cache"))
+
+			analyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+			diagnostics := analyzer.Analyze()
+			if HasErrors(diagnostics) {
+				t.Fatalf("unexpected diagnostics: %v", diagnostics)
+			}
+			for _, identifier := range []string{"example.com/app/dto Item", "example.com/app/lib/go-labels Label"} {
+				if _, ok := analyzer.Definitions.Types[identifier]; !ok {
+					t.Fatalf("dependency type %s is not registered", identifier)
+				}
+			}
+
+			if err := cache.Store(module, filter, analyzer.Inputs(), analyzer.Definitions, diagnostics); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.change != nil {
+				tt.change(t, module.Root)
+			}
+			if tt.filter != nil {
+				filter, _ = NewFileFilter(tt.filter, nil)
+			}
+
+			// module is loaded again by the next run, module mode could change
+			module, err := LoadModule(module.Root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			definitions, _, inputs, ok := cache.Load(module, filter)
+			if ok != tt.hit {
+				t.Fatalf("cache hit %v, want %v", ok, tt.hit)
+			}
+			if !ok {
+				return
+			}
+
+			if len(definitions.Controllers) != 1 || len(definitions.Types) != 2 {
+				t.Errorf("unexpected definitions %+v", definitions)
+			}
+
+			// package clauses of standard packages are read as well
+			dirs := inputs.PackageDirs()
+			for _, dir := range []string{"dto", "lib/go-labels", "lib/go-util"} {
+				if !slices.Contains(dirs, filepath.Join(module.Root, dir)) {
+					t.Errorf("package dirs %v, %s is missing", dirs, dir)
+				}
+			}
+		})
+	}
+}
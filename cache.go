@@ -0,0 +1,105 @@
+package restc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// FileCache keeps parsed files between analyses, a file is parsed again when its modification time or size changes.
+// All files share the cache file set, so the cache could be reused by analyzers of the same project, e.g. in watch mode
+type FileCache struct {
+	fset *token.FileSet
+
+	mu    sync.Mutex
+	files map[string]cachedFile
+}
+
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	hash    string
+	file    *ast.File
+	err     error
+}
+
+func NewFileCache() *FileCache {
+	return &FileCache{
+		fset:  token.NewFileSet(),
+		files: make(map[string]cachedFile),
+	}
+}
+
+func (c *FileCache) FileSet() *token.FileSet {
+	return c.fset
+}
+
+// ParseFile parses file with comments or returns cached result if the file is not changed
+func (c *FileCache) ParseFile(filename string) (*ast.File, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.files[filename]
+	c.mu.Unlock()
+
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.file, cached.err
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := parser.ParseFile(c.fset, filename, src, parser.ParseComments)
+
+	c.mu.Lock()
+	c.files[filename] = cachedFile{modTime: info.ModTime(), size: info.Size(), hash: hashContent(src), file: file, err: err}
+	c.mu.Unlock()
+
+	return file, err
+}
+
+// ParseFiles parses files concurrently, parsed files and errors are in the order of filenames
+func (c *FileCache) ParseFiles(filenames []string) ([]*ast.File, []error) {
+	files := make([]*ast.File, len(filenames))
+	errs := make([]error, len(filenames))
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, filename := range filenames {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer wg.Done()
+			files[i], errs[i] = c.ParseFile(filename)
+			<-workers
+		}()
+	}
+	wg.Wait()
+
+	return files, errs
+}
+
+// Hash returns content hash of the parsed file, the content is hashed when the file is read for parsing
+func (c *FileCache) Hash(filename string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.files[filename]
+	return cached.hash, ok
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
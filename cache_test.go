@@ -0,0 +1,91 @@
+package restc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		modTime  time.Duration
+		reparsed bool
+	}{
+		{name: "unchanged", content: "package a\n"},
+		{name: "touched", content: "package a\n", modTime: time.Second, reparsed: true},
+		{name: "content of another size", content: "package abc\n", reparsed: true},
+		{name: "content of the same size", content: "package b\n", modTime: time.Second, reparsed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "a.go")
+			writeTestFile(t, filename, "package a\n")
+
+			modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+			if err := os.Chtimes(filename, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+
+			cache := NewFileCache()
+			first, err := cache.ParseFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash, _ := cache.Hash(filename)
+
+			writeTestFile(t, filename, tt.content)
+			if err := os.Chtimes(filename, modTime.Add(tt.modTime), modTime.Add(tt.modTime)); err != nil {
+				t.Fatal(err)
+			}
+
+			second, err := cache.ParseFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reparsed := first != second; reparsed != tt.reparsed {
+				t.Errorf("reparsed %v, want %v", reparsed, tt.reparsed)
+			}
+			if second.Name.Name != tt.content[len("package "):len(tt.content)-1] {
+				t.Errorf("package name %s", second.Name.Name)
+			}
+
+			current, _ := cache.Hash(filename)
+			if changed := current != hash; changed != (tt.content != "package a\n") {
+				t.Errorf("hash changed %v", changed)
+			}
+		})
+	}
+}
+
+func TestFileCacheParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	filenames := []string{
+		filepath.Join(dir, "a.go"),
+		filepath.Join(dir, "missing.go"),
+		filepath.Join(dir, "invalid.go"),
+		filepath.Join(dir, "b.go"),
+	}
+	writeTestFile(t, filenames[0], "package a\n")
+	writeTestFile(t, filenames[2], "package\n")
+	writeTestFile(t, filenames[3], "package b\n")
+
+	files, errs := NewFileCache().ParseFiles(filenames)
+
+	if errs[0] != nil || files[0].Name.Name != "a" {
+		t.Errorf("a.go: %v", errs[0])
+	}
+	if errs[1] == nil {
+		t.Error("missing.go: error expected")
+	}
+	if errs[2] == nil {
+		t.Error("invalid.go: error expected")
+	}
+	if errs[3] != nil || files[3].Name.Name != "b" {
+		t.Errorf("b.go: %v", errs[3])
+	}
+}
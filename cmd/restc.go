@@ -1,99 +1,554 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"io/fs"
 	"log/slog"
+	"maps"
 	"os"
-	"os/exec"
-	"path"
 	"path/filepath"
-	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/tulinowpavel/restc"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "generate":
+			os.Exit(generate(logger, os.Args[2:], false))
+		case "check":
+			os.Exit(generate(logger, os.Args[2:], true))
+		case "watch":
+			os.Exit(watch(logger, os.Args[2:]))
+		case "dump":
+			os.Exit(dump(logger, os.Args[2:]))
+		}
+	}
+
+	os.Exit(run(logger, os.Args[1:]))
+}
+
+// generate runs all plugins of the project config, in check mode generated files are compared with files on disk
+func generate(logger *slog.Logger, args []string, check bool) int {
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	configFlag := flags.String("config", restc.ConfigFileName, "project config path")
+	dryRunFlag := new(bool)
+	if !check {
+		dryRunFlag = flags.Bool("dry-run", false, "print generated files without writing them")
+	}
+	flags.Parse(args)
+
+	p, err := configProject(*configFlag)
+	if err != nil {
+		logger.Error("cannot load config", "error", err)
+		return 1
+	}
+
+	files, _, ok := analyzeAndGenerate(logger, restc.NewFileCache(), p)
+	if !ok {
+		return 1
+	}
+
+	if check {
+		return checkFiles(logger, p, files)
+	}
+
+	return writeFiles(logger, files, *dryRunFlag)
+}
+
+// project is the analyzed module root with plugins to run
+type project struct {
+	root     string
+	analysis restc.AnalysisMode
+	cacheDir string
+	filter   restc.FileFilter
+	plugins  []restc.PluginConfig
+}
+
+func configProject(configPath string) (project, error) {
+	config, err := restc.LoadConfig(configPath)
+	if err != nil {
+		return project{}, err
+	}
+
+	filter, err := restc.NewFileFilter(config.Include, config.Exclude)
+	if err != nil {
+		return project{}, fmt.Errorf("cannot compile file patterns: %w", err)
+	}
+
+	return project{root: config.Root, analysis: config.Analysis, cacheDir: config.Cache, filter: filter, plugins: config.Plugins}, nil
+}
+
+// analysisFlags are names of flags registered by projectFlags
+var analysisFlags = []string{"path", "pattern", "analysis", "cache"}
+
+// projectFlags registers flags configuring analyzed project without config, returned function builds project
+// without plugins after flags are parsed
+func projectFlags(flags *flag.FlagSet) (load func() (project, error)) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		panic(err)
 	}
 
-	projectRootFlag := flag.String("path", cwd, "project root path")
-	filePatternFlag := flag.String("pattern", `\.go$`, "pattern for files with controllers")
-	outputFlag := flag.String("output", ".", "output path")
-	useShellFlag := flag.Bool("shell", false, "invoke plugin via system shell")
-	pluginFlag := flag.String("plugin", "", "generator plugin")
+	projectRootFlag := flags.String("path", cwd, "project root path")
+	filePatternFlag := flags.String("pattern", `\.go$`, "pattern for files with controllers")
+	analysisFlag := flags.String("analysis", string(restc.AnalysisAST), "analysis mode, ast or packages")
+	cacheFlag := flags.String("cache", "", "directory of the analysis results cache, disabled when empty")
+
+	return func() (project, error) {
+		projectRoot, err := filepath.Abs(*projectRootFlag)
+		if err != nil {
+			return project{}, fmt.Errorf("cannot determine project root absolute path: %w", err)
+		}
+
+		analysis := restc.AnalysisMode(*analysisFlag)
+		if err := analysis.Validate(); err != nil {
+			return project{}, err
+		}
+
+		filter, err := restc.NewFileFilter([]string{*filePatternFlag}, nil)
+		if err != nil {
+			return project{}, fmt.Errorf("cannot compile controller file regex pattern: %w", err)
+		}
+
+		cacheDir := *cacheFlag
+		if cacheDir != "" {
+			if cacheDir, err = filepath.Abs(cacheDir); err != nil {
+				return project{}, fmt.Errorf("cannot determine cache path: %w", err)
+			}
+		}
+
+		return project{root: projectRoot, analysis: analysis, cacheDir: cacheDir, filter: filter}, nil
+	}
+}
+
+// pluginFlags registers flags configuring single plugin run, returned function builds project after flags are parsed
+func pluginFlags(flags *flag.FlagSet) (pluginFlag *string, load func() (project, error)) {
+	loadProject := projectFlags(flags)
+	outputFlag := flags.String("output", ".", "output directory")
+	useShellFlag := flags.Bool("shell", false, "invoke plugin via system shell")
+	pluginFlag = flags.String("plugin", "", "generator plugin")
+
+	pluginOptions := make(map[string]string)
+	flags.Func("opt", "generator plugin option key=value, could be repeated", func(option string) error {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return fmt.Errorf("option must be key=value")
+		}
+		pluginOptions[key] = value
+		return nil
+	})
+
+	return pluginFlag, func() (project, error) {
+		p, err := loadProject()
+		if err != nil {
+			return project{}, err
+		}
+
+		outputPath, err := filepath.Abs(*outputFlag)
+		if err != nil {
+			return project{}, fmt.Errorf("cannot determine output path: %w", err)
+		}
+
+		plugin := restc.PluginConfig{
+			Output:  outputPath,
+			Options: pluginOptions,
+		}
+		if *useShellFlag {
+			plugin.Command = *pluginFlag
+		} else {
+			plugin.Name = *pluginFlag
+		}
+
+		p.plugins = []restc.PluginConfig{plugin}
+		return p, nil
+	}
+}
+
+// run invokes single plugin configured with flags
+func run(logger *slog.Logger, args []string) int {
+	flags := flag.NewFlagSet("restc", flag.ExitOnError)
+	_, load := pluginFlags(flags)
+	dryRunFlag := flags.Bool("dry-run", false, "print generated files without writing them")
+	flags.Parse(args)
+
+	p, err := load()
+	if err != nil {
+		logger.Error("invalid flags", "error", err)
+		return 1
+	}
+
+	files, _, ok := analyzeAndGenerate(logger, restc.NewFileCache(), p)
+	if !ok {
+		return 1
+	}
+
+	return writeFiles(logger, files, *dryRunFlag)
+}
+
+// watch regenerates code whenever analyzed files, go.mod or the project config change,
+// unchanged files are not parsed again between runs
+func watch(logger *slog.Logger, args []string) int {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	configFlag := flags.String("config", restc.ConfigFileName, "project config path, ignored when -plugin is set")
+	intervalFlag := flags.Duration("interval", 500*time.Millisecond, "file changes polling interval")
+	pluginFlag, loadFlags := pluginFlags(flags)
+	flags.Parse(args)
+
+	load := func() (project, []string, error) {
+		if *pluginFlag != "" {
+			p, err := loadFlags()
+			return p, nil, err
+		}
+
+		p, err := configProject(*configFlag)
+		return p, []string{*configFlag}, err
+	}
+
+	cache := restc.NewFileCache()
+	var previous map[string]fileState
+	// packages read by the last analysis besides project files, e.g. of referenced types
+	var packageDirs []string
+
+	for ; ; time.Sleep(*intervalFlag) {
+		p, extra, err := load()
+		if err != nil {
+			if previous == nil {
+				logger.Error("cannot load project", "error", err)
+				return 1
+			}
+
+			// config could be saved partially, wait for the next change
+			current := fileStates(extra)
+			if !maps.Equal(previous, current) {
+				logger.Error("cannot load project", "error", err)
+				previous = current
+			}
+			continue
+		}
+
+		current := snapshot(p, packageDirs, extra)
+		if maps.Equal(previous, current) {
+			continue
+		}
+
+		if previous != nil {
+			logger.Info("files changed, regenerating")
+		}
+
+		files, dirs, ok := analyzeAndGenerate(logger, cache, p)
+		packageDirs = dirs
+		if ok {
+			written, err := restc.WriteFiles(files)
+			if err != nil {
+				logger.Error("cannot write generated files", "error", err)
+			} else {
+				logger.Info("generated files written", "files", len(written))
+			}
+		}
+
+		// generated files could match the filter, take snapshot after they are written
+		previous = snapshot(p, packageDirs, extra)
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot returns states of project files, current go files of package directories, go.mod and extra files
+func snapshot(p project, packageDirs, extra []string) map[string]fileState {
+	files, _ := restc.ProjectFiles(p.root, p.filter)
+	for _, dir := range packageDirs {
+		packageFiles, _ := restc.PackageFiles(dir)
+		files = append(files, packageFiles...)
+	}
+	files = append(files, filepath.Join(p.root, "go.mod"))
+	return fileStates(append(files, extra...))
+}
+
+// fileStates returns modification time and size of files, missing files are omitted
+func fileStates(files []string) map[string]fileState {
+	states := make(map[string]fileState, len(files))
+	for _, name := range files {
+		if info, err := os.Stat(name); err == nil {
+			states[name] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	return states
+}
 
-	flag.Parse()
+// dump writes analyzed definitions in JSON or YAML, the project is configured with the config
+// unless any of analysis flags is set
+func dump(logger *slog.Logger, args []string) int {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	configFlag := flags.String("config", restc.ConfigFileName, "project config path, could not be combined with analysis flags")
+	formatFlag := flags.String("format", "json", "output format, json or yaml")
+	outFlag := flags.String("out", "-", "output file, - for stdout")
+	loadFlags := projectFlags(flags)
+	flags.Parse(args)
+
+	if *formatFlag != "json" && *formatFlag != "yaml" {
+		logger.Error("unknown dump format", "format", *formatFlag)
+		return 1
+	}
 
-	projectRoot, err := filepath.Abs(*projectRootFlag)
+	configSet, flagsSet := false, false
+	flags.Visit(func(f *flag.Flag) {
+		configSet = configSet || f.Name == "config"
+		flagsSet = flagsSet || slices.Contains(analysisFlags, f.Name)
+	})
+	if configSet && flagsSet {
+		logger.Error("invalid flags", "error", "-config could not be combined with -"+strings.Join(analysisFlags, ", -"))
+		return 1
+	}
+
+	var p project
+	var err error
+	if flagsSet {
+		p, err = loadFlags()
+	} else {
+		p, err = configProject(*configFlag)
+	}
 	if err != nil {
-		logger.Error("cannot determine project root absolute path", "error", err)
-		os.Exit(1)
+		logger.Error("cannot load project", "error", err)
+		return 1
 	}
 
-	patternRegex, err := regexp.Compile(*filePatternFlag)
+	_, definitions, _, ok := analyze(logger, restc.NewFileCache(), p)
+	if !ok {
+		return 1
+	}
+
+	content, err := json.MarshalIndent(definitions, "", "  ")
 	if err != nil {
-		logger.Error("cannot compile controller file regex pattern", "error", err)
-		os.Exit(1)
+		panic(err)
+	}
+	content = append(content, '\n')
+
+	if *formatFlag == "yaml" {
+		// marshaled JSON keeps json tags and keys order of definitions
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			panic(err)
+		}
+		resetStyle(&node)
+
+		content, err = yaml.Marshal(&node)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if *outFlag == "-" {
+		os.Stdout.Write(content)
+		return 0
 	}
 
-	outputPath, err := filepath.Abs(*outputFlag)
+	out, err := filepath.Abs(*outFlag)
 	if err != nil {
 		logger.Error("cannot determine output path", "error", err)
-		os.Exit(1)
+		return 1
 	}
 
-	modRegex := regexp.MustCompile(`module (.+)`)
-	modFile, err := os.ReadFile(path.Join(projectRoot, "go.mod"))
+	return writeFiles(logger, []restc.GeneratedFile{{Path: out, Content: string(content)}}, false)
+}
+
+// resetStyle drops JSON flow style from yaml nodes
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetStyle(n)
+	}
+}
+
+// analyze loads module and collects its definitions, diagnostics are printed to stderr.
+// Directories of packages read besides project files are returned even if the analysis fails
+func analyze(logger *slog.Logger, cache *restc.FileCache, p project) (*restc.Module, restc.Definitions, []string, bool) {
+	module, err := restc.LoadModule(p.root)
 	if err != nil {
-		logger.Error("cannot read go.mod file", "error", err)
-		os.Exit(1)
+		logger.Error("cannot load module", "error", err)
+		return nil, restc.Definitions{}, nil, false
 	}
 
-	subMatches := modRegex.FindStringSubmatch(string(modFile))
-	if len(subMatches) != 2 {
-		logger.Error("cannot find module name in go.mod file", "match", subMatches)
-		os.Exit(1)
+	var definitions restc.Definitions
+	var diagnostics []restc.Diagnostic
+	var packageDirs []string
+	if p.analysis == restc.AnalysisPackages {
+		pa := restc.NewPackagesAnalyzer(logger, module, p.filter)
+		diagnostics, definitions, packageDirs = pa.Analyze(), pa.Definitions, pa.PackageDirs()
+	} else {
+		definitions, diagnostics, packageDirs = analyzeAST(logger, cache, module, p)
 	}
 
-	moduleName := string(subMatches[1])
-	if moduleName == "" {
-		logger.Error("empty module name in go.mod file")
-		os.Exit(1)
+	for _, d := range diagnostics {
+		fmt.Fprintln(os.Stderr, d.String())
 	}
 
-	rg := restc.NewRestCompilerAnalyzer(logger, moduleName, projectRoot, patternRegex)
-	rg.Analyze()
+	if restc.HasErrors(diagnostics) {
+		logger.Error("analysis failed", "diagnostics", len(diagnostics))
+		return nil, restc.Definitions{}, packageDirs, false
+	}
 
-	logger.Info("invoke generator plugin", "plugin", *pluginFlag)
+	return module, definitions, packageDirs, true
+}
 
-	def, _ := json.Marshal(rg.Definitions)
+// analyzeAST runs AST analysis, results are reused from the on-disk cache when it is configured and inputs are unchanged
+func analyzeAST(logger *slog.Logger, cache *restc.FileCache, module *restc.Module, p project) (restc.Definitions, []restc.Diagnostic, []string) {
+	var diskCache *restc.AnalysisCache
+	if p.cacheDir != "" {
+		diskCache = restc.NewAnalysisCache(p.cacheDir)
+		if definitions, diagnostics, inputs, ok := diskCache.Load(module, p.filter); ok {
+			logger.Debug("analysis results loaded from cache")
+			return definitions, diagnostics, inputs.PackageDirs()
+		}
+	}
 
-	var cmd *exec.Cmd
-	if *useShellFlag {
-		// TODO: detect system shell
-		cmd = exec.Command("zsh", "-c", *pluginFlag)
-	} else {
-		cmd = exec.Command("restc-" + *pluginFlag)
+	rg := restc.NewRestCompilerAnalyzerWithCache(logger, module, p.filter, cache)
+	diagnostics := rg.Analyze()
+	inputs := rg.Inputs()
+
+	if diskCache != nil && !restc.HasErrors(diagnostics) {
+		if err := diskCache.Store(module, p.filter, inputs, rg.Definitions, diagnostics); err != nil {
+			logger.Warn("cannot write analysis cache", "error", err)
+		}
+	}
+
+	return rg.Definitions, diagnostics, inputs.PackageDirs()
+}
+
+// analyzeAndGenerate analyzes the project and runs plugins, files are returned only when all plugins succeed.
+// Directories of packages read by the analysis are returned as well
+func analyzeAndGenerate(logger *slog.Logger, cache *restc.FileCache, p project) ([]restc.GeneratedFile, []string, bool) {
+	module, definitions, packageDirs, ok := analyze(logger, cache, p)
+	if !ok {
+		return nil, packageDirs, false
+	}
+
+	for _, plugin := range p.plugins {
+		logger.Info("invoke generator plugin", "plugin", plugin.DisplayName())
 	}
 
-	cmd.Env = append(
-		os.Environ(),
-		"RESTC_OUTPUT="+outputPath,
-	)
+	failed := false
+	var files []restc.GeneratedFile
+	for _, result := range restc.RunPlugins(module, definitions, p.plugins) {
+		if result.Err != nil {
+			logger.Error("invoke generator plugin error", "plugin", result.Plugin.DisplayName(), "error", result.Err)
+			failed = true
+			continue
+		}
 
-	cmd.Stdin = bytes.NewBuffer(def)
-	cmd.Stdout = os.Stdout
+		for _, d := range result.Response.Diagnostics {
+			fmt.Fprintln(os.Stderr, d.String())
+		}
+
+		if restc.HasErrors(result.Response.Diagnostics) {
+			logger.Error("generation failed", "plugin", result.Plugin.DisplayName(), "diagnostics", len(result.Response.Diagnostics))
+			failed = true
+			continue
+		}
+
+		files = append(files, result.OutputFiles()...)
+	}
+
+	return files, packageDirs, !failed
+}
+
+func writeFiles(logger *slog.Logger, files []restc.GeneratedFile, dryRun bool) int {
+	if dryRun {
+		for _, f := range files {
+			fmt.Printf("%s (%d bytes)\n", f.Path, len(f.Content))
+		}
+		return 0
+	}
+
+	written, err := restc.WriteFiles(files)
+	if err != nil {
+		logger.Error("cannot write generated files", "error", err)
+		return 1
+	}
+
+	logger.Info("generated files written", "files", len(written))
+
+	return 0
+}
+
+// checkFiles prints unified diff of stale files, files with generated header left in output directories
+// and directories of generated files are reported as deleted
+func checkFiles(logger *slog.Logger, p project, files []restc.GeneratedFile) int {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+
+	displayName := func(path string) string {
+		if rel, err := filepath.Rel(cwd, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+		return path
+	}
+
+	stale := 0
+	for _, f := range files {
+		current, err := os.ReadFile(f.Path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.Error("cannot read generated file", "file", f.Path, "error", err)
+			return 1
+		}
+
+		name := displayName(f.Path)
+
+		oldName := "a/" + name
+		if current == nil {
+			oldName = "/dev/null"
+		}
+
+		if diff := restc.UnifiedDiff(oldName, "b/"+name, string(current), f.Content); diff != "" {
+			fmt.Print(diff)
+			stale++
+		}
+	}
+
+	dirs := make([]string, 0, len(p.plugins)+len(files))
+	for _, plugin := range p.plugins {
+		dirs = append(dirs, plugin.Output)
+	}
+	for _, f := range files {
+		dirs = append(dirs, filepath.Dir(f.Path))
+	}
+
+	orphaned, err := restc.OrphanedFiles(dirs, files)
+	if err != nil {
+		logger.Error("cannot find orphaned generated files", "error", err)
+		return 1
+	}
+
+	for _, path := range orphaned {
+		current, err := os.ReadFile(path)
+		if err != nil {
+			logger.Error("cannot read generated file", "file", path, "error", err)
+			return 1
+		}
+
+		fmt.Print(restc.UnifiedDiff("a/"+displayName(path), "/dev/null", string(current), ""))
+		stale++
+	}
 
-	if err := cmd.Run(); err != nil {
-		logger.Error("invoke generator plugin error", "error", err)
-		os.Exit(0)
+	if stale > 0 {
+		logger.Error("generated files are stale, run restc generate", "files", stale)
+		return 1
 	}
 
-	// TODO: write output into file
+	return 0
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+)
+
+func TestWatchSnapshot(t *testing.T) {
+	analyses := []restc.AnalysisMode{restc.AnalysisAST, restc.AnalysisPackages}
+
+	for _, analysis := range analyses {
+		t.Run(string(analysis), func(t *testing.T) {
+			root := t.TempDir()
+			files := map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.22\n",
+				"task/task.go": `package task
+
+import "example.com/app/dto"
+
+// @Controller /
+type TaskController struct{}
+
+// @Responder
+type TaskResponder interface {
+	// @Status 200
+	Ok(label dto.Label)
+}
+
+// @Resource GET /tasks
+func (c *TaskController) List(r TaskResponder) error { return nil }
+`,
+				"dto/dto.go":      "package dto\n\ntype Label struct{ Name string }\n",
+				"dto/notes.txt":   "labels\n",
+				"other/other.go":  "package other\n",
+				"restc.yaml":      "include: ['^task/']\n",
+				"extra/extra.txt": "extra\n",
+			}
+			for name, content := range files {
+				writeFile(t, filepath.Join(root, name), content)
+			}
+
+			filter, err := restc.NewFileFilter([]string{"^task/"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := project{root: root, analysis: analysis, filter: filter}
+			extra := []string{filepath.Join(root, "restc.yaml")}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			_, _, packageDirs, ok := analyze(logger, restc.NewFileCache(), p)
+			if !ok {
+				t.Fatal("analysis failed")
+			}
+
+			tests := []struct {
+				name    string
+				file    string
+				changed bool
+			}{
+				{name: "unrelated package file", file: "other/other.go"},
+				{name: "non go file of read package", file: "dto/notes.txt"},
+				{name: "file outside of project", file: "extra/extra.txt"},
+				{name: "project file", file: "task/task.go", changed: true},
+				{name: "package file read by analysis", file: "dto/dto.go", changed: true},
+				{name: "new package file read by analysis", file: "dto/label.go", changed: true},
+				{name: "go.mod", file: "go.mod", changed: true},
+				{name: "extra file", file: "restc.yaml", changed: true},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					previous := snapshot(p, packageDirs, extra)
+
+					// appended content changes the size, so the change is seen regardless of mtime resolution
+					appendFile(t, filepath.Join(root, tt.file), "\n// changed\n")
+
+					current := snapshot(p, packageDirs, extra)
+					if changed := !maps.Equal(previous, current); changed != tt.changed {
+						t.Errorf("got changed %v, want %v", changed, tt.changed)
+					}
+				})
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendFile(t *testing.T, filename, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,237 @@
+package restc
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the default project config file name
+const ConfigFileName = "restc.yaml"
+
+// Config is the project config, relative paths are resolved against the config file directory
+//
+//	root: .
+//	analysis: ast
+//	cache: .restc-cache
+//	include: ['^internal/']
+//	exclude: ['_gen\.go$']
+//	plugins:
+//	  - name: gin
+//	    output: internal/server
+//	    options:
+//	      package: server
+//	  - command: ./bin/custom-generator
+//	    output: api
+type Config struct {
+	Root     string         `yaml:"root"`
+	Analysis AnalysisMode   `yaml:"analysis"`
+	Cache    string         `yaml:"cache"`
+	Include  []string       `yaml:"include"`
+	Exclude  []string       `yaml:"exclude"`
+	Plugins  []PluginConfig `yaml:"plugins"`
+}
+
+// AnalysisMode selects analyzer: ast resolves types by parsing package files, packages uses go/packages and go/types
+type AnalysisMode string
+
+const (
+	AnalysisAST      AnalysisMode = "ast"
+	AnalysisPackages AnalysisMode = "packages"
+)
+
+// Validate reports unknown modes, empty mode is ast
+func (m AnalysisMode) Validate() error {
+	switch m {
+	case "", AnalysisAST, AnalysisPackages:
+		return nil
+	}
+	return fmt.Errorf("unknown analysis mode %q, ast or packages expected", m)
+}
+
+// PluginConfig is a single plugin run, Name invokes restc-<name> executable, Command is executed via system shell
+type PluginConfig struct {
+	Name    string            `yaml:"name"`
+	Command string            `yaml:"command"`
+	Output  string            `yaml:"output"`
+	Options map[string]string `yaml:"options"`
+}
+
+// LoadConfig reads config file and resolves its paths into absolute ones
+func LoadConfig(configPath string) (*Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("cannot parse config file %s: %w", configPath, err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	config.Root = absPath(dir, config.Root)
+	if config.Cache != "" {
+		config.Cache = absPath(dir, config.Cache)
+	}
+
+	if err := config.Analysis.Validate(); err != nil {
+		return nil, err
+	}
+
+	for i, p := range config.Plugins {
+		if (p.Name == "") == (p.Command == "") {
+			return nil, fmt.Errorf("plugin #%d must have either name or command", i+1)
+		}
+		config.Plugins[i].Output = absPath(dir, p.Output)
+	}
+
+	return &config, nil
+}
+
+func absPath(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(dir, p)
+}
+
+// DisplayName returns plugin name or command for logs
+func (p PluginConfig) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Command
+}
+
+// Cmd returns command invoking the plugin
+func (p PluginConfig) Cmd() *exec.Cmd {
+	if p.Name != "" {
+		return exec.Command("restc-" + p.Name)
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	return exec.Command(shell, "-c", p.Command)
+}
+
+// PluginResult is the outcome of the plugin run
+type PluginResult struct {
+	Plugin   PluginConfig
+	Response PluginResponse
+	Err      error
+}
+
+// OutputFiles returns response files with absolute paths resolved against the plugin output directory
+func (r PluginResult) OutputFiles() []GeneratedFile {
+	files := make([]GeneratedFile, 0, len(r.Response.Files))
+	for _, f := range r.Response.Files {
+		files = append(files, GeneratedFile{
+			Path:    filepath.Join(r.Plugin.Output, filepath.FromSlash(f.Path)),
+			Content: f.Content,
+		})
+	}
+	return files
+}
+
+// RunPlugins runs plugins in parallel, results are in the order of plugins
+func RunPlugins(module *Module, definitions Definitions, plugins []PluginConfig) []PluginResult {
+	results := make([]PluginResult, len(plugins))
+
+	wg := sync.WaitGroup{}
+	for i, p := range plugins {
+		wg.Add(1)
+		go func(i int, p PluginConfig) {
+			defer wg.Done()
+
+			response, err := RunPlugin(p.Cmd(), PluginRequest{
+				Definitions: definitions,
+				Options:     p.Options,
+				ModulePath:  module.Path,
+				ModuleRoot:  module.Root,
+				Output:      p.Output,
+			})
+			if err == nil {
+				err = ValidateFilePaths(p, module.Root, response.Files)
+			}
+			// plugins report definition positions relative to the module root
+			for j, d := range response.Diagnostics {
+				if d.File != "" && !filepath.IsAbs(d.File) {
+					response.Diagnostics[j].File = filepath.Join(module.Root, filepath.FromSlash(d.File))
+				}
+			}
+
+			results[i] = PluginResult{Plugin: p, Response: response, Err: err}
+		}(i, p)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// FileFilter selects go files for analysis by their slash separated path relative to the module root,
+// files matching any of include patterns (all files if there are none) and none of exclude patterns are selected
+type FileFilter struct {
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+}
+
+func NewFileFilter(include, exclude []string) (FileFilter, error) {
+	var filter FileFilter
+
+	for _, pattern := range include {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return filter, fmt.Errorf("invalid include pattern: %w", err)
+		}
+		filter.Include = append(filter.Include, re)
+	}
+
+	for _, pattern := range exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return filter, fmt.Errorf("invalid exclude pattern: %w", err)
+		}
+		filter.Exclude = append(filter.Exclude, re)
+	}
+
+	return filter, nil
+}
+
+func (f FileFilter) Match(filePath string) bool {
+	if !strings.HasSuffix(filePath, ".go") {
+		return false
+	}
+
+	included := len(f.Include) == 0
+	for _, re := range f.Include {
+		if re.MatchString(filePath) {
+			included = true
+			break
+		}
+	}
+
+	if !included {
+		return false
+	}
+
+	for _, re := range f.Exclude {
+		if re.MatchString(filePath) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,160 @@
+package restc
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config", ConfigFileName)
+	writeTestFile(t, configPath, `root: ..
+analysis: packages
+cache: .restc-cache
+include: ['^internal/']
+plugins:
+  - name: gin
+    output: ../internal/server
+    options:
+      package: server
+  - command: ./bin/custom-generator
+    output: /src/api
+`)
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Root != dir {
+		t.Errorf("got root %s, want %s", config.Root, dir)
+	}
+	if want := filepath.Join(dir, "config", ".restc-cache"); config.Cache != want {
+		t.Errorf("got cache %s, want %s", config.Cache, want)
+	}
+	if config.Analysis != AnalysisPackages {
+		t.Errorf("got analysis %s", config.Analysis)
+	}
+	if len(config.Plugins) != 2 {
+		t.Fatalf("got plugins %+v", config.Plugins)
+	}
+	if want := filepath.Join(dir, "internal", "server"); config.Plugins[0].Output != want {
+		t.Errorf("got output %s, want %s", config.Plugins[0].Output, want)
+	}
+	if config.Plugins[0].Options["package"] != "server" {
+		t.Errorf("got options %v", config.Plugins[0].Options)
+	}
+	if want := filepath.FromSlash("/src/api"); config.Plugins[1].Output != want {
+		t.Errorf("got output %s, want %s", config.Plugins[1].Output, want)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "name and command",
+			config: "plugins:\n  - name: gin\n  - name: openapi\n    command: ./gen\n",
+			err:    "plugin #2 must have either name or command",
+		},
+		{
+			name:   "neither name nor command",
+			config: "plugins:\n  - output: api\n",
+			err:    "plugin #1 must have either name or command",
+		},
+		{
+			name:   "unknown analysis mode",
+			config: "analysis: types\n",
+			err:    `unknown analysis mode "types", ast or packages expected`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), ConfigFileName)
+			writeTestFile(t, configPath, tt.config)
+
+			_, err := LoadConfig(configPath)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("got error %v, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFileFilterMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		path    string
+		match   bool
+	}{
+		{name: "no patterns", path: "task/task.go", match: true},
+		{name: "not go file", path: "task/task.txt"},
+		{name: "included", include: []string{"^internal/"}, path: "internal/task/task.go", match: true},
+		{name: "not included", include: []string{"^internal/"}, path: "cmd/main.go"},
+		{name: "any include", include: []string{"^internal/", "^cmd/"}, path: "cmd/main.go", match: true},
+		{name: "excluded", exclude: []string{"_gen\\.go$"}, path: "task/task_gen.go"},
+		{name: "exclude wins over include", include: []string{"^internal/"}, exclude: []string{"_gen\\.go$"}, path: "internal/server/gin_gen.go"},
+		{name: "included and not excluded", include: []string{"^internal/"}, exclude: []string{"_gen\\.go$"}, path: "internal/task/task.go", match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFileFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := filter.Match(tt.path); got != tt.match {
+				t.Errorf("got %v, want %v", got, tt.match)
+			}
+		})
+	}
+
+	if _, err := NewFileFilter([]string{"("}, nil); err == nil {
+		t.Error("error expected for invalid include pattern")
+	}
+	if _, err := NewFileFilter(nil, []string{"("}); err == nil {
+		t.Error("error expected for invalid exclude pattern")
+	}
+}
+
+func TestRunPluginsDiagnosticFiles(t *testing.T) {
+	t.Setenv("SHELL", "")
+
+	module := testModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+	})
+	absolute := filepath.Join(t.TempDir(), "other.go")
+
+	response := fmt.Sprintf(`{"definitionsVersion": %d, "diagnostics": [`+
+		`{"severity": "warning", "message": "relative", "file": "task/task.go", "line": 3},`+
+		`{"severity": "warning", "message": "absolute", "file": %q},`+
+		`{"severity": "warning", "message": "without file"}]}`, DefinitionsVersion, absolute)
+
+	results := RunPlugins(module, NewDefinitions(), []PluginConfig{
+		{Command: fmt.Sprintf("cat >/dev/null; printf '%%s' '%s'", response), Output: filepath.Join(module.Root, "api")},
+	})
+	if len(results) != 1 {
+		t.Fatalf("got results %+v", results)
+	}
+	if results[0].Err != nil {
+		t.Fatal(results[0].Err)
+	}
+
+	want := []string{filepath.Join(module.Root, "task", "task.go"), absolute, ""}
+	diagnostics := results[0].Response.Diagnostics
+	if len(diagnostics) != len(want) {
+		t.Fatalf("got diagnostics %+v", diagnostics)
+	}
+	for i, d := range diagnostics {
+		if d.File != want[i] {
+			t.Errorf("diagnostic %s: got file %q, want %q", d.Message, d.File, want[i])
+		}
+	}
+}
@@ -1,6 +1,15 @@
 package restc
 
+import "fmt"
+
+// DefinitionsVersion is the version of Definitions wire format described by definitions.schema.json,
+// it is increased on incompatible changes. Definitions without version are of version 1
+//
+// Version 2 renamed Parameter "kind" key into "source" and added structured type references
+const DefinitionsVersion = 2
+
 type Definitions struct {
+	Version int      `json:"version"`
 	Imports []string `json:"imports"`
 
 	Types       map[string]TypeSchema `json:"types"`
@@ -10,36 +19,61 @@ type Definitions struct {
 
 func NewDefinitions() Definitions {
 	return Definitions{
+		Version:     DefinitionsVersion,
 		Types:       make(map[string]TypeSchema),
 		Responders:  make(map[string]Responder),
 		Controllers: make(map[string]Controller),
 	}
 }
 
+// Position is the location of the definition name in the source,
+// File is slash separated path relative to the module root or absolute path for files outside of it
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// String formats position as file:line:column
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
 type TypeSchema struct {
-	Name   string  `json:"name"`
-	Alias  string  `json:"alias"`
-	Schema *Schema `json:"schema,omitempty"`
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	Alias   string `json:"alias"`
+	// Underlying is the basic underlying type of types declared over predeclared types, e.g. int8 or uint64,
+	// byte and rune are resolved into uint8 and int32
+	Underlying string    `json:"underlying,omitempty"`
+	Schema     *Schema   `json:"schema,omitempty"`
+	Position   *Position `json:"position,omitempty"`
 }
 
 type Responder struct {
+	Package   string     `json:"package"`
 	Name      string     `json:"name"`
 	Responses []Response `json:"responses,omitempty"`
+	Position  *Position  `json:"position,omitempty"`
 }
 
 type Response struct {
 	Name        string              `json:"name"`
 	Annotations map[string][]string `json:"annotations"`
 	Params      []Parameter         `json:"params"`
+	Position    *Position           `json:"position,omitempty"`
 }
 
+// Parameter is the resource or responder method param, Type is the type identifier and TypeRef is its structured form
 type Parameter struct {
-	Source   ParameterSource `json:"kind,omitempty"`
+	Source   ParameterSource `json:"source,omitempty"`
 	Type     string          `json:"type"`
+	TypeRef  *TypeRef        `json:"typeRef"`
 	Name     string          `json:"name"`
 	Metadata string          `json:"metadata,omitempty"`
 
-	Schema *Schema `json:"schema,omitempty"`
+	Schema   *Schema   `json:"schema,omitempty"`
+	Position *Position `json:"position,omitempty"`
 }
 
 type ParameterSource string
@@ -56,7 +90,8 @@ const (
 type Controller struct {
 	// FIXME: for what
 	Package string `json:"package"`
-	File    string `json:"file"`
+	// File is the import path of the package joined with the file name
+	File string `json:"file"`
 
 	Name  string `json:"name"`
 	Alias string `json:"alias"`
@@ -64,6 +99,7 @@ type Controller struct {
 	Base string `json:"base"`
 
 	Resources map[string]Resource `json:"resources"`
+	Position  *Position           `json:"position,omitempty"`
 }
 
 type Resource struct {
@@ -78,4 +114,6 @@ type Resource struct {
 	Summary string   `json:"summary,omitempty"`
 	Details string   `json:"details,omitempty"`
 	Tags    []string `json:"tags,omitempty"`
+
+	Position *Position `json:"position,omitempty"`
 }
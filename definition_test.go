@@ -0,0 +1,293 @@
+package restc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testAPIFiles is a module with controller, responder and types referenced in various ways
+var testAPIFiles = map[string]string{
+	"go.mod": "module example.com/app\n\ngo 1.22\n",
+	"dto/dto.go": `package dto
+
+type Status string
+
+type Label struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Tags []string
+
+type Audit struct {
+	By string ` + "`json:\"by\"`" + `
+}
+`,
+	"task/task.go": `package task
+
+import (
+	"context"
+	"time"
+
+	"example.com/app/dto"
+)
+
+type Base struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Task struct {
+	Base
+	*dto.Audit
+	dto.Tags
+	Title  string               ` + "`json:\"title\"`" + `
+	Parent *Task                ` + "`json:\"parent,omitempty\"`" + `
+	Labels []dto.Label          ` + "`json:\"labels\"`" + `
+	Meta   map[string]dto.Label ` + "`json:\"meta\"`" + `
+	Hash   [16]byte             ` + "`json:\"hash\"`" + `
+	Status dto.Status           ` + "`json:\"status\"`" + `
+}
+
+type ErrorBody struct {
+	Message string ` + "`json:\"message\"`" + `
+}
+
+type TaskResponder interface {
+	// @Status 200
+	Ok(task Task)
+	// @Status 404
+	NotFound(e ErrorBody)
+	// @Status 403
+	Forbidden()
+}
+
+// @Controller /api/tasks
+type TaskController struct{}
+
+// Get task
+//
+// Returns task by id
+// @Resource GET /{id}
+// @Tag tasks
+// @Param id Path
+// @Param org Header X-Org
+// @Param since Query
+func (c *TaskController) GetTask(ctx context.Context, r TaskResponder, id string, org *string, since *time.Time) error {
+	return nil
+}
+
+// @Resource POST /
+// @Param body Body
+func (c *TaskController) CreateTask(ctx context.Context, r TaskResponder, body Task) error {
+	return nil
+}
+
+// @Resource GET /
+func (c *TaskController) ListTasks(r TaskResponder) error {
+	return nil
+}
+`,
+}
+
+func TestDefinitionsSchema(t *testing.T) {
+	module := testModule(t, testAPIFiles)
+
+	content, err := os.ReadFile("definitions.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	filter, _ := NewFileFilter([]string{`^task/`}, nil)
+
+	astAnalyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+	packagesAnalyzer := NewPackagesAnalyzer(testLogger(), module, filter)
+
+	analyzers := map[AnalysisMode]func() ([]Diagnostic, Definitions){
+		AnalysisAST: func() ([]Diagnostic, Definitions) {
+			return astAnalyzer.Analyze(), astAnalyzer.Definitions
+		},
+		AnalysisPackages: func() ([]Diagnostic, Definitions) {
+			return packagesAnalyzer.Analyze(), packagesAnalyzer.Definitions
+		},
+	}
+
+	for mode, analyze := range analyzers {
+		t.Run(string(mode), func(t *testing.T) {
+			diagnostics, definitions := analyze()
+			if HasErrors(diagnostics) {
+				t.Fatalf("unexpected diagnostics: %v", diagnostics)
+			}
+			if len(definitions.Controllers) != 1 || len(definitions.Responders) != 1 || len(definitions.Types) == 0 {
+				t.Fatalf("unexpected definitions %+v", definitions)
+			}
+
+			if file := definitions.Controllers["TaskController"].File; file != "example.com/app/task/task.go" {
+				t.Errorf("controller file %q", file)
+			}
+
+			dump, err := json.Marshal(definitions)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var value any
+			if err := json.Unmarshal(dump, &value); err != nil {
+				t.Fatal(err)
+			}
+
+			v := schemaValidator{root: schema}
+			v.validate("", schema, value)
+			for _, e := range v.errors {
+				t.Error(e)
+			}
+		})
+	}
+}
+
+func TestAnalyzersDefinitions(t *testing.T) {
+	module := testModule(t, testAPIFiles)
+	filter, _ := NewFileFilter([]string{`^task/`}, nil)
+
+	astAnalyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+	if diagnostics := astAnalyzer.Analyze(); HasErrors(diagnostics) {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	packagesAnalyzer := NewPackagesAnalyzer(testLogger(), module, filter)
+	if diagnostics := packagesAnalyzer.Analyze(); HasErrors(diagnostics) {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	want, _ := json.MarshalIndent(astAnalyzer.Definitions, "", "  ")
+	got, _ := json.MarshalIndent(packagesAnalyzer.Definitions, "", "  ")
+	if diff := UnifiedDiff("ast", "packages", string(want), string(got)); diff != "" {
+		t.Errorf("definitions differ:\n%s", diff)
+	}
+}
+
+// schemaValidator checks JSON values against the subset of JSON Schema used by definitions.schema.json.
+// Objects are validated strictly: properties missing in the schema are reported unless additionalProperties
+// is set, so fields added to definitions must be described in the schema
+type schemaValidator struct {
+	root   map[string]any
+	errors []string
+}
+
+func (v *schemaValidator) errorf(path, format string, args ...any) {
+	v.errors = append(v.errors, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) validate(path string, schema map[string]any, value any) {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := v.root["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if !ok {
+			v.errorf(path, "unknown $ref %s", ref)
+			return
+		}
+		v.validate(path, def, value)
+	}
+
+	if expected, ok := schema["const"]; ok && !reflect.DeepEqual(expected, value) {
+		v.errorf(path, "%v is not %v", value, expected)
+	}
+	if values, ok := schema["enum"].([]any); ok && !slices.Contains(values, value) {
+		v.errorf(path, "%v is not one of %v", value, values)
+	}
+	if minimum, ok := schema["minimum"].(float64); ok {
+		if n, ok := value.(float64); ok && n < minimum {
+			v.errorf(path, "%v is less than %v", n, minimum)
+		}
+	}
+
+	switch types := schema["type"].(type) {
+	case string:
+		v.checkType(path, []any{types}, value)
+	case []any:
+		v.checkType(path, types, value)
+	}
+
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, s := range allOf {
+			sub := s.(map[string]any)
+			if cond, ok := sub["if"].(map[string]any); ok {
+				probe := schemaValidator{root: v.root}
+				probe.validate(path, cond, value)
+				if len(probe.errors) == 0 {
+					v.validate(path, sub["then"].(map[string]any), value)
+				}
+				continue
+			}
+			v.validate(path, sub, value)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := value[name.(string)]; !ok {
+					v.errorf(path, "missing required property %s", name)
+				}
+			}
+		}
+
+		properties, hasProperties := schema["properties"].(map[string]any)
+		additional, hasAdditional := schema["additionalProperties"].(map[string]any)
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			switch {
+			case properties[name] != nil:
+				v.validate(path+"/"+name, properties[name].(map[string]any), value[name])
+			case hasAdditional:
+				v.validate(path+"/"+name, additional, value[name])
+			case hasProperties:
+				v.errorf(path, "property %s is not described", name)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.validate(fmt.Sprintf("%s/%d", path, i), items, item)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) checkType(path string, types []any, value any) {
+	var actual string
+	switch value := value.(type) {
+	case nil:
+		actual = "null"
+	case bool:
+		actual = "boolean"
+	case string:
+		actual = "string"
+	case float64:
+		actual = "number"
+		if value == float64(int64(value)) && slices.Contains(types, any("integer")) {
+			actual = "integer"
+		}
+	case []any:
+		actual = "array"
+	case map[string]any:
+		actual = "object"
+	}
+
+	if !slices.Contains(types, any(actual)) {
+		v.errorf(path, "%s is not %v", actual, types)
+	}
+}
@@ -0,0 +1,75 @@
+package restc
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found during analysis
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+}
+
+func NewDiagnostic(severity Severity, position token.Position, message string) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		Message:  message,
+		File:     position.Filename,
+		Line:     position.Line,
+		Column:   position.Column,
+	}
+}
+
+// String formats diagnostic in compiler style: file:line:column: severity: message
+func (d Diagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location += fmt.Sprintf(":%d:%d", d.Line, d.Column)
+	}
+
+	if location == "" {
+		return string(d.Severity) + ": " + d.Message
+	}
+
+	return location + ": " + string(d.Severity) + ": " + d.Message
+}
+
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorDiagnostics converts go/parser errors into diagnostics, file is used for another errors
+func ErrorDiagnostics(file string, err error) []Diagnostic {
+	var errorList scanner.ErrorList
+	if errors.As(err, &errorList) {
+		diagnostics := make([]Diagnostic, 0, len(errorList))
+		for _, e := range errorList {
+			diagnostics = append(diagnostics, NewDiagnostic(SeverityError, e.Pos, e.Msg))
+		}
+		return diagnostics
+	}
+
+	return []Diagnostic{{
+		Severity: SeverityError,
+		Message:  err.Error(),
+		File:     file,
+	}}
+}
@@ -0,0 +1,93 @@
+package restc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	// lines returns lines of letters from..to inclusive, 1 is "a"
+	lines := func(from, to int) string {
+		sb := strings.Builder{}
+		for i := from; i <= to; i++ {
+			sb.WriteString(string(rune('a'+i-1)) + "\n")
+		}
+		return sb.String()
+	}
+
+	tests := []struct {
+		name       string
+		oldName    string
+		oldContent string
+		newContent string
+		want       string
+	}{
+		{
+			name:       "equal",
+			oldContent: "a\nb\n",
+			newContent: "a\nb\n",
+			want:       "",
+		},
+		{
+			name:       "new file",
+			oldName:    "/dev/null",
+			newContent: "a\nb\n",
+			want:       "--- /dev/null\n+++ b/x.go\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:       "deleted file",
+			oldContent: "a\n",
+			want:       "--- a/x.go\n+++ b/x.go\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:       "changed line",
+			oldContent: lines(1, 5),
+			newContent: "a\nb\nC\nd\ne\n",
+			want:       "--- a/x.go\n+++ b/x.go\n@@ -1,5 +1,5 @@\n a\n b\n-c\n+C\n d\n e\n",
+		},
+		{
+			name:       "context is limited",
+			oldContent: lines(1, 10),
+			newContent: lines(1, 9) + "J\n",
+			want:       "--- a/x.go\n+++ b/x.go\n@@ -7,4 +7,4 @@\n g\n h\n i\n-j\n+J\n",
+		},
+		{
+			name:       "added line",
+			oldContent: "a\nc\n",
+			newContent: "a\nb\nc\n",
+			want:       "--- a/x.go\n+++ b/x.go\n@@ -1,2 +1,3 @@\n a\n+b\n c\n",
+		},
+		{
+			name:       "missing trailing newline",
+			oldContent: "a\nb",
+			newContent: "a\nb\n",
+			want:       "--- a/x.go\n+++ b/x.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:       "close changes share hunk",
+			oldContent: lines(1, 10),
+			newContent: "A\n" + lines(2, 7) + "H\n" + lines(9, 10),
+			want:       "--- a/x.go\n+++ b/x.go\n@@ -1,10 +1,10 @@\n-a\n+A\n b\n c\n d\n e\n f\n g\n-h\n+H\n i\n j\n",
+		},
+		{
+			name:       "distant changes split hunks",
+			oldContent: lines(1, 12),
+			newContent: "A\n" + lines(2, 11) + "L\n",
+			want: "--- a/x.go\n+++ b/x.go\n@@ -1,4 +1,4 @@\n-a\n+A\n b\n c\n d\n" +
+				"@@ -9,4 +9,4 @@\n i\n j\n k\n-l\n+L\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldName := tt.oldName
+			if oldName == "" {
+				oldName = "a/x.go"
+			}
+
+			if got := UnifiedDiff(oldName, "b/x.go", tt.oldContent, tt.newContent); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
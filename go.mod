@@ -1,11 +1,17 @@
 module github.com/tulinowpavel/restc
 
-go 1.21.0
+go 1.22.0
+
+require (
+	github.com/wk8/go-ordered-map/v2 v2.1.8
+	golang.org/x/mod v0.20.0
+	golang.org/x/tools v0.24.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
-	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/sync v0.8.0 // indirect
 )
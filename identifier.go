@@ -0,0 +1,109 @@
+package restc
+
+import (
+	"strings"
+)
+
+// Composite type identifiers are built from the element identifier with Go type prefixes:
+//
+//	*full/path/to/package TypeName
+//	[]full/path/to/package TypeName
+//	[4]byte
+//	map[string]full/path/to/package TypeName
+//
+// pointers mark optional (nullable) values
+
+func IsPointerTypeIdentifier(identifier string) bool {
+	return strings.HasPrefix(identifier, "*")
+}
+
+func IsSliceTypeIdentifier(identifier string) bool {
+	return strings.HasPrefix(identifier, "[]")
+}
+
+func IsArrayTypeIdentifier(identifier string) bool {
+	return strings.HasPrefix(identifier, "[") && !IsSliceTypeIdentifier(identifier)
+}
+
+func IsMapTypeIdentifier(identifier string) bool {
+	return strings.HasPrefix(identifier, "map[")
+}
+
+func IsCompositeTypeIdentifier(identifier string) bool {
+	return IsPointerTypeIdentifier(identifier) || strings.HasPrefix(identifier, "[") || IsMapTypeIdentifier(identifier)
+}
+
+// ElemTypeIdentifier strips one level of pointer, slice, array or map from the type identifier
+func ElemTypeIdentifier(identifier string) string {
+	switch {
+	case IsPointerTypeIdentifier(identifier):
+		return identifier[1:]
+	case strings.HasPrefix(identifier, "["):
+		return identifier[strings.Index(identifier, "]")+1:]
+	case IsMapTypeIdentifier(identifier):
+		_, value := splitMapTypeIdentifier(identifier)
+		return value
+	}
+	return identifier
+}
+
+// MapKeyTypeIdentifier returns key type identifier of the map type identifier
+func MapKeyTypeIdentifier(identifier string) string {
+	key, _ := splitMapTypeIdentifier(identifier)
+	return key
+}
+
+// ArrayLenTypeIdentifier returns length of the array type identifier
+func ArrayLenTypeIdentifier(identifier string) string {
+	if !IsArrayTypeIdentifier(identifier) {
+		return ""
+	}
+	return identifier[1:strings.Index(identifier, "]")]
+}
+
+// BaseTypeIdentifier strips all composite levels and returns named or primitive type identifier
+func BaseTypeIdentifier(identifier string) string {
+	for IsCompositeTypeIdentifier(identifier) {
+		identifier = ElemTypeIdentifier(identifier)
+	}
+	return identifier
+}
+
+// FormatTypeIdentifier converts type identifier into Go type expression,
+// qualify formats named types
+func FormatTypeIdentifier(identifier string, qualify func(packagePath, name string) string) string {
+	switch {
+	case IsPointerTypeIdentifier(identifier):
+		return "*" + FormatTypeIdentifier(ElemTypeIdentifier(identifier), qualify)
+	case IsSliceTypeIdentifier(identifier):
+		return "[]" + FormatTypeIdentifier(ElemTypeIdentifier(identifier), qualify)
+	case IsArrayTypeIdentifier(identifier):
+		return "[" + ArrayLenTypeIdentifier(identifier) + "]" + FormatTypeIdentifier(ElemTypeIdentifier(identifier), qualify)
+	case IsMapTypeIdentifier(identifier):
+		key, value := splitMapTypeIdentifier(identifier)
+		return "map[" + FormatTypeIdentifier(key, qualify) + "]" + FormatTypeIdentifier(value, qualify)
+	}
+
+	packagePath, name, ok := strings.Cut(identifier, " ")
+	if !ok {
+		return identifier
+	}
+	return qualify(packagePath, name)
+}
+
+// splitMapTypeIdentifier splits map[K]V identifier into key and value identifiers, key could be composite itself
+func splitMapTypeIdentifier(identifier string) (string, string) {
+	depth := 0
+	for i := len("map"); i < len(identifier); i++ {
+		switch identifier[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return identifier[len("map["):i], identifier[i+1:]
+			}
+		}
+	}
+	return "", identifier
+}
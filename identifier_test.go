@@ -0,0 +1,138 @@
+package restc
+
+import (
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestCompositeTypeIdentifiers(t *testing.T) {
+	tests := []struct {
+		identifier string
+		pointer    bool
+		slice      bool
+		array      bool
+		mapType    bool
+		elem       string
+		key        string
+		length     string
+		base       string
+	}{
+		{identifier: "string", elem: "string", base: "string"},
+		{identifier: "example.com/app Task", elem: "example.com/app Task", base: "example.com/app Task"},
+		{identifier: "*example.com/app Task", pointer: true, elem: "example.com/app Task", base: "example.com/app Task"},
+		{identifier: "[]*example.com/app Task", slice: true, elem: "*example.com/app Task", base: "example.com/app Task"},
+		{identifier: "[4]byte", array: true, elem: "byte", length: "4", base: "byte"},
+		{identifier: "[][2]int", slice: true, elem: "[2]int", base: "int"},
+		{identifier: "map[string]example.com/app Task", mapType: true, elem: "example.com/app Task", key: "string", base: "example.com/app Task"},
+		{identifier: "map[[2]int]map[string]bool", mapType: true, elem: "map[string]bool", key: "[2]int", base: "bool"},
+		{identifier: "map[example.com/app Key][]string", mapType: true, elem: "[]string", key: "example.com/app Key", base: "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			if got := IsPointerTypeIdentifier(tt.identifier); got != tt.pointer {
+				t.Errorf("IsPointerTypeIdentifier = %v", got)
+			}
+			if got := IsSliceTypeIdentifier(tt.identifier); got != tt.slice {
+				t.Errorf("IsSliceTypeIdentifier = %v", got)
+			}
+			if got := IsArrayTypeIdentifier(tt.identifier); got != tt.array {
+				t.Errorf("IsArrayTypeIdentifier = %v", got)
+			}
+			if got := IsMapTypeIdentifier(tt.identifier); got != tt.mapType {
+				t.Errorf("IsMapTypeIdentifier = %v", got)
+			}
+			if got := IsCompositeTypeIdentifier(tt.identifier); got != (tt.pointer || tt.slice || tt.array || tt.mapType) {
+				t.Errorf("IsCompositeTypeIdentifier = %v", got)
+			}
+			if got := ElemTypeIdentifier(tt.identifier); got != tt.elem {
+				t.Errorf("ElemTypeIdentifier = %q, want %q", got, tt.elem)
+			}
+			if got := MapKeyTypeIdentifier(tt.identifier); tt.mapType && got != tt.key {
+				t.Errorf("MapKeyTypeIdentifier = %q, want %q", got, tt.key)
+			}
+			if got := ArrayLenTypeIdentifier(tt.identifier); got != tt.length {
+				t.Errorf("ArrayLenTypeIdentifier = %q, want %q", got, tt.length)
+			}
+			if got := BaseTypeIdentifier(tt.identifier); got != tt.base {
+				t.Errorf("BaseTypeIdentifier = %q, want %q", got, tt.base)
+			}
+		})
+	}
+}
+
+func TestFormatTypeIdentifier(t *testing.T) {
+	qualify := func(packagePath, name string) string {
+		return ImportPathName(packagePath) + "." + name
+	}
+
+	tests := map[string]string{
+		"string":                                "string",
+		"time Time":                             "time.Time",
+		"*example.com/app/task Task":            "*task.Task",
+		"[]*example.com/app/task Task":          "[]*task.Task",
+		"[4]byte":                               "[4]byte",
+		"map[string][]example.com/app/dto Item": "map[string][]dto.Item",
+		"map[example.com/app/dto Key]*error":    "map[dto.Key]*error",
+	}
+
+	for identifier, want := range tests {
+		if got := FormatTypeIdentifier(identifier, qualify); got != want {
+			t.Errorf("FormatTypeIdentifier(%q) = %q, want %q", identifier, got, want)
+		}
+	}
+}
+
+func TestResolveCompositeIdentifierExpr(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod":              "module example.com/app\n\ngo 1.22\n",
+		"internal/dto/dto.go": "package dto\n",
+	})
+
+	trctx := NewTypeResolvingContext(module, "example.com/app/task", []*ast.ImportSpec{
+		{Path: &ast.BasicLit{Value: `"example.com/app/internal/dto"`}},
+	})
+
+	tests := []struct {
+		expr       string
+		identifier string
+		err        bool
+	}{
+		{expr: "*Task", identifier: "*example.com/app/task Task"},
+		{expr: "[]*dto.Label", identifier: "[]*example.com/app/internal/dto Label"},
+		{expr: "[4]byte", identifier: "[4]byte"},
+		{expr: "[]*[2]Task", identifier: "[]*[2]example.com/app/task Task"},
+		{expr: "map[string][]dto.Label", identifier: "map[string][]example.com/app/internal/dto Label"},
+		{expr: "map[dto.Status]*error", identifier: "map[example.com/app/internal/dto Status]*error"},
+		{expr: "[N]byte", err: true},
+		{expr: "[]unknown.Task", err: true},
+		{expr: "map[string]func()", err: true},
+	}
+
+	r := NewTypeResolver(NewFileCache())
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identifier, err := r.ResolveIdentifierExpr(trctx, expr)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("error expected, got %q", identifier)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identifier != tt.identifier {
+				t.Errorf("got %q, want %q", identifier, tt.identifier)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package restc
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// Module is the analyzed project module, it locates package directories on disk
+// via go.mod requires and replace directives, vendor directory and module cache without network access
+type Module struct {
+	Path string
+	Root string
+
+	requires map[string]string
+	replaces []*modfile.Replace
+	vendor   bool
+	modCache string
+	goRoot   string
+
+	packageNames map[string]string
+	// packageClauses maps directories of packages with looked up names to go files whose package clauses were read,
+	// the file is empty when the package has no go files
+	packageClauses map[string]string
+}
+
+// LoadModule reads go.mod file in the project root
+func LoadModule(projectRoot string) (*Module, error) {
+	goModPath := filepath.Join(projectRoot, "go.mod")
+
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read go.mod file: %w", err)
+	}
+
+	mf, err := modfile.Parse(goModPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse go.mod file: %w", err)
+	}
+
+	if mf.Module == nil || mf.Module.Mod.Path == "" {
+		return nil, fmt.Errorf("empty module name in go.mod file")
+	}
+
+	m := &Module{
+		Path:           mf.Module.Mod.Path,
+		Root:           projectRoot,
+		requires:       make(map[string]string, len(mf.Require)),
+		replaces:       mf.Replace,
+		modCache:       moduleCacheDir(),
+		goRoot:         build.Default.GOROOT,
+		packageNames:   make(map[string]string),
+		packageClauses: make(map[string]string),
+	}
+
+	for _, r := range mf.Require {
+		m.requires[r.Mod.Path] = r.Mod.Version
+	}
+
+	// vendor directory is used by go command unless another module mode is requested
+	if _, err := os.Stat(filepath.Join(projectRoot, "vendor", "modules.txt")); err == nil {
+		goFlags := os.Getenv("GOFLAGS")
+		m.vendor = !strings.Contains(goFlags, "-mod=mod") && !strings.Contains(goFlags, "-mod=readonly")
+	}
+
+	return m, nil
+}
+
+func moduleCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+
+	return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+}
+
+// PackageDir returns directory of the imported package
+func (m *Module) PackageDir(importPath string) (string, error) {
+	if importPath == m.Path || strings.HasPrefix(importPath, m.Path+"/") {
+		return filepath.Join(m.Root, strings.TrimPrefix(strings.TrimPrefix(importPath, m.Path), "/")), nil
+	}
+
+	if IsStandardPackage(importPath) {
+		return existingDir(filepath.Join(m.goRoot, "src", importPath))
+	}
+
+	if m.vendor {
+		return existingDir(filepath.Join(m.Root, "vendor", importPath))
+	}
+
+	modulePath, version := m.requiredModule(importPath)
+	if modulePath == "" {
+		return "", fmt.Errorf("package %s is not provided by any required module", importPath)
+	}
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(importPath, modulePath), "/")
+
+	moduleDir, err := m.moduleDir(modulePath, version)
+	if err != nil {
+		return "", err
+	}
+
+	return existingDir(filepath.Join(moduleDir, rel))
+}
+
+// requiredModule finds required module with the longest path prefix of the import path
+func (m *Module) requiredModule(importPath string) (string, string) {
+	var modulePath, version string
+	for p, v := range m.requires {
+		if (importPath == p || strings.HasPrefix(importPath, p+"/")) && len(p) > len(modulePath) {
+			modulePath, version = p, v
+		}
+	}
+	return modulePath, version
+}
+
+func (m *Module) moduleDir(modulePath, version string) (string, error) {
+	for _, r := range m.replaces {
+		if r.Old.Path != modulePath || (r.Old.Version != "" && r.Old.Version != version) {
+			continue
+		}
+
+		if modfile.IsDirectoryPath(r.New.Path) {
+			if filepath.IsAbs(r.New.Path) {
+				return r.New.Path, nil
+			}
+			return filepath.Join(m.Root, r.New.Path), nil
+		}
+
+		modulePath, version = r.New.Path, r.New.Version
+		break
+	}
+
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(m.modCache, escapedPath+"@"+escapedVersion)
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("module %s@%s is not found in module cache, run go mod download", modulePath, version)
+	}
+
+	return dir, nil
+}
+
+// PackageName returns name declared in the package clause of the imported package
+func (m *Module) PackageName(importPath string) (string, error) {
+	if name, ok := m.packageNames[importPath]; ok {
+		return name, nil
+	}
+
+	dir, err := m.PackageDir(importPath)
+	if err != nil {
+		return "", err
+	}
+
+	fn, err := packageClauseFile(dir)
+	if err != nil {
+		return "", err
+	}
+
+	m.packageClauses[dir] = fn
+	if fn == "" {
+		return "", fmt.Errorf("no go files in package %s", importPath)
+	}
+
+	fast, err := parser.ParseFile(token.NewFileSet(), fn, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+
+	m.packageNames[importPath] = fast.Name.Name
+	return fast.Name.Name, nil
+}
+
+// packageClauseFile returns the first non-test go file of the directory, its package clause declares the package name
+func packageClauseFile(dir string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", err
+	}
+
+	for _, fn := range files {
+		if !strings.HasSuffix(fn, "_test.go") {
+			return fn, nil
+		}
+	}
+
+	return "", nil
+}
+
+var majorVersionSuffixRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// ImportPathName guesses package name by import path: last element without major version suffix
+func ImportPathName(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	name := parts[len(parts)-1]
+
+	if len(parts) > 1 && majorVersionSuffixRegex.MatchString(name) {
+		name = parts[len(parts)-2]
+	}
+
+	// gopkg.in/yaml.v3
+	if strings.HasPrefix(importPath, "gopkg.in/") {
+		name, _, _ = strings.Cut(name, ".")
+	}
+
+	return name
+}
+
+// IsStandardPackage reports whether import path belongs to the standard library (no dot in the first element)
+func IsStandardPackage(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+func existingDir(dir string) (string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("package directory %s not found", dir)
+	}
+
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return dir, nil
+}
@@ -0,0 +1,198 @@
+package restc
+
+import (
+	"go/ast"
+	"go/build"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackageDir(t *testing.T) {
+	tests := []struct {
+		name       string
+		goMod      string
+		files      []string
+		cacheFiles []string
+		goFlags    string
+		importPath string
+		// want is the directory relative to the module root, to the module cache with "cache:" prefix
+		// or to GOROOT with "goroot:" prefix
+		want string
+		err  bool
+	}{
+		{name: "module package", importPath: "example.com/app/internal/dto", want: "internal/dto"},
+		{name: "module root", importPath: "example.com/app", want: "."},
+		{name: "standard package", importPath: "net/http", want: "goroot:src/net/http"},
+		{name: "missing standard package", importPath: "net/missing", err: true},
+		{
+			name:       "module cache",
+			goMod:      "require example.com/lib v1.2.0\n",
+			cacheFiles: []string{"example.com/lib@v1.2.0/dto/dto.go"},
+			importPath: "example.com/lib/dto",
+			want:       "cache:example.com/lib@v1.2.0/dto",
+		},
+		{
+			name:       "module cache escapes upper case letters",
+			goMod:      "require github.com/Org/Lib v1.0.0-RC\n",
+			cacheFiles: []string{"github.com/!org/!lib@v1.0.0-!r!c/lib.go"},
+			importPath: "github.com/Org/Lib",
+			want:       "cache:github.com/!org/!lib@v1.0.0-!r!c",
+		},
+		{
+			name:  "longest required module path",
+			goMod: "require (\n\texample.com/lib v1.2.0\n\texample.com/lib/sub v0.1.0\n)\n",
+			cacheFiles: []string{
+				"example.com/lib@v1.2.0/sub/pkg/pkg.go",
+				"example.com/lib/sub@v0.1.0/pkg/pkg.go",
+			},
+			importPath: "example.com/lib/sub/pkg",
+			want:       "cache:example.com/lib/sub@v0.1.0/pkg",
+		},
+		{
+			name:       "module missing in cache",
+			goMod:      "require example.com/lib v1.2.0\n",
+			importPath: "example.com/lib/dto",
+			err:        true,
+		},
+		{name: "module not required", importPath: "example.com/lib/dto", err: true},
+		{
+			name:       "replace with directory",
+			goMod:      "require example.com/lib v1.2.0\n\nreplace example.com/lib => ./third_party/lib\n",
+			files:      []string{"third_party/lib/dto/dto.go"},
+			cacheFiles: []string{"example.com/lib@v1.2.0/dto/dto.go"},
+			importPath: "example.com/lib/dto",
+			want:       "third_party/lib/dto",
+		},
+		{
+			name:       "replace with module",
+			goMod:      "require example.com/lib v1.2.0\n\nreplace example.com/lib => example.com/fork v1.3.0\n",
+			cacheFiles: []string{"example.com/lib@v1.2.0/dto/dto.go", "example.com/fork@v1.3.0/dto/dto.go"},
+			importPath: "example.com/lib/dto",
+			want:       "cache:example.com/fork@v1.3.0/dto",
+		},
+		{
+			name:       "replace of another version",
+			goMod:      "require example.com/lib v1.2.0\n\nreplace example.com/lib v1.1.0 => ./third_party/lib\n",
+			files:      []string{"third_party/lib/dto/dto.go"},
+			cacheFiles: []string{"example.com/lib@v1.2.0/dto/dto.go"},
+			importPath: "example.com/lib/dto",
+			want:       "cache:example.com/lib@v1.2.0/dto",
+		},
+		{
+			name:       "vendor",
+			goMod:      "require example.com/lib v1.2.0\n",
+			files:      []string{"vendor/modules.txt", "vendor/example.com/lib/dto/dto.go"},
+			cacheFiles: []string{"example.com/lib@v1.2.0/dto/dto.go"},
+			importPath: "example.com/lib/dto",
+			want:       "vendor/example.com/lib/dto",
+		},
+		{
+			name:       "vendor of not required module",
+			files:      []string{"vendor/modules.txt", "vendor/example.com/lib/dto/dto.go"},
+			importPath: "example.com/lib/dto",
+			want:       "vendor/example.com/lib/dto",
+		},
+		{
+			name:       "vendor package missing",
+			goMod:      "require example.com/lib v1.2.0\n",
+			files:      []string{"vendor/modules.txt"},
+			cacheFiles: []string{"example.com/lib@v1.2.0/dto/dto.go"},
+			importPath: "example.com/lib/dto",
+			err:        true,
+		},
+		{
+			name:       "vendor disabled with GOFLAGS",
+			goMod:      "require example.com/lib v1.2.0\n",
+			files:      []string{"vendor/modules.txt", "vendor/example.com/lib/dto/dto.go"},
+			cacheFiles: []string{"example.com/lib@v1.2.0/dto/dto.go"},
+			goFlags:    "-mod=mod",
+			importPath: "example.com/lib/dto",
+			want:       "cache:example.com/lib@v1.2.0/dto",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			for _, name := range tt.cacheFiles {
+				writeTestFile(t, filepath.Join(cacheDir, name), "package "+filepath.Base(filepath.Dir(name))+"\n")
+			}
+			t.Setenv("GOMODCACHE", cacheDir)
+			t.Setenv("GOFLAGS", tt.goFlags)
+
+			files := map[string]string{"go.mod": "module example.com/app\n\ngo 1.22\n\n" + tt.goMod}
+			for _, name := range tt.files {
+				files[name] = "package " + filepath.Base(filepath.Dir(name)) + "\n"
+			}
+			files["internal/dto/dto.go"] = "package dto\n"
+
+			module := testModule(t, files)
+
+			dir, err := module.PackageDir(tt.importPath)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("error expected, got %s", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := filepath.Join(module.Root, tt.want)
+			if rel, ok := strings.CutPrefix(tt.want, "cache:"); ok {
+				want = filepath.Join(cacheDir, rel)
+			} else if rel, ok := strings.CutPrefix(tt.want, "goroot:"); ok {
+				want = filepath.Join(build.Default.GOROOT, rel)
+			}
+			if dir != want {
+				t.Errorf("got %s, want %s", dir, want)
+			}
+		})
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod":                   "module example.com/app\n\ngo 1.22\n",
+		"internal/dto/dto.go":      "package dto\n",
+		"internal/model/models.go": "package models\n",
+		"internal/api/v2/api.go":   "package api\n",
+		"internal/empty/doc.txt":   "",
+	})
+
+	trctx := NewTypeResolvingContext(module, "example.com/app/task", []*ast.ImportSpec{
+		{Path: &ast.BasicLit{Value: `"time"`}},
+		{Path: &ast.BasicLit{Value: `"example.com/app/internal/dto"`}},
+		{Name: ast.NewIdent("m"), Path: &ast.BasicLit{Value: `"example.com/app/internal/dto"`}},
+		{Path: &ast.BasicLit{Value: `"example.com/app/internal/empty"`}},
+		{Path: &ast.BasicLit{Value: `"example.com/app/internal/model"`}},
+		{Path: &ast.BasicLit{Value: `"example.com/app/internal/api/v2"`}},
+	})
+
+	tests := []struct {
+		alias      string
+		importPath string
+		ok         bool
+	}{
+		{alias: "time", importPath: "time", ok: true},
+		{alias: "dto", importPath: "example.com/app/internal/dto", ok: true},
+		{alias: "m", importPath: "example.com/app/internal/dto", ok: true},
+		// package names differ from the last import path element, they are read from package clauses
+		{alias: "models", importPath: "example.com/app/internal/model", ok: true},
+		{alias: "api", importPath: "example.com/app/internal/api/v2", ok: true},
+		// package without go files is named after the import path
+		{alias: "empty", importPath: "example.com/app/internal/empty", ok: true},
+		{alias: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			importPath, ok := trctx.ImportPath(tt.alias)
+			if ok != tt.ok || importPath != tt.importPath {
+				t.Errorf("got %q %v, want %q %v", importPath, ok, tt.importPath, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,580 @@
+package restc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// PackagesAnalyzer collects the same definitions as RestCompilerAnalyzer from packages loaded and type checked
+// with golang.org/x/tools/go/packages, so aliases, dot imports, embedded types, instantiated generic types
+// and build constraints are resolved like the compiler does.
+//
+// Dependencies are type checked from source instead of export data, so the analyzer does not depend
+// on the export data format of the installed toolchain, function bodies of dependencies are skipped.
+type PackagesAnalyzer struct {
+	analysis
+	// parsed files of loaded packages and their dependencies
+	files map[string]*ast.File
+	// doc comments of interface methods by name position, files are indexed on demand
+	docs    map[token.Pos]*ast.CommentGroup
+	indexed map[string]bool
+}
+
+func NewPackagesAnalyzer(logger *slog.Logger, module *Module, filter FileFilter) PackagesAnalyzer {
+	return PackagesAnalyzer{
+		analysis: newAnalysis(logger, module, filter, token.NewFileSet()),
+		files:    make(map[string]*ast.File),
+		docs:     make(map[token.Pos]*ast.CommentGroup),
+		indexed:  make(map[string]bool),
+	}
+}
+
+// Analyze loads packages of project files and collects definitions, problems are returned as diagnostics.
+// Type errors are reported as warnings, definitions referring to invalid types are reported as errors
+func (r *PackagesAnalyzer) Analyze() []Diagnostic {
+	files, diagnostics := ProjectFiles(r.module.Root, r.filter)
+	r.diagnostics = append(r.diagnostics, diagnostics...)
+
+	selected := make(map[string]bool, len(files))
+	dirs := make(map[string]bool)
+	patterns := make([]string, 0)
+	for _, file := range files {
+		selected[file] = true
+
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+
+		pattern := "."
+		if rel, err := filepath.Rel(r.module.Root, dir); err == nil && rel != "." {
+			pattern = "./" + filepath.ToSlash(rel)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	if len(patterns) == 0 {
+		return r.finish()
+	}
+
+	r.logger.Debug("load packages", "packages", len(patterns))
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:  r.module.Root,
+		Fset: r.fset,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			file, err := parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+			if file != nil && !dirs[filepath.Dir(filename)] {
+				// only declarations of dependencies are needed
+				for _, decl := range file.Decls {
+					if fn, ok := decl.(*ast.FuncDecl); ok {
+						fn.Body = nil
+					}
+				}
+			}
+			return file, err
+		},
+	}, patterns...)
+	if err != nil {
+		r.diagnostics = append(r.diagnostics, ErrorDiagnostics(r.module.Root, err)...)
+		return r.diagnostics
+	}
+
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, file := range pkg.Syntax {
+			r.files[r.fset.Position(file.Package).Filename] = file
+		}
+	})
+
+	slices.SortFunc(pkgs, func(a, b *packages.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
+
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			r.diagnostics = append(r.diagnostics, packageErrorDiagnostic(e))
+		}
+
+		if pkg.TypesInfo == nil {
+			continue
+		}
+
+		for _, file := range pkg.Syntax {
+			filename := r.fset.Position(file.Package).Filename
+			if !selected[filename] {
+				continue
+			}
+
+			r.logger.Debug("analyze file", "file", strings.TrimPrefix(filename, r.module.Root+"/"))
+
+			r.AnalyzeFile(pkg, file, filepath.Base(filename))
+		}
+	}
+
+	return r.finish()
+}
+
+// packageErrorDiagnostic converts package loading error, type errors are reported as warnings
+func packageErrorDiagnostic(e packages.Error) Diagnostic {
+	severity := SeverityError
+	if e.Kind == packages.TypeError {
+		severity = SeverityWarning
+	}
+
+	d := Diagnostic{Severity: severity, Message: e.Msg}
+
+	// position is file:line:column, file:line or file
+	parts := strings.Split(e.Pos, ":")
+	for i := 0; i < 2 && len(parts) > 1; i++ {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		d.Line, d.Column = n, d.Line
+		parts = parts[:len(parts)-1]
+	}
+	if e.Pos != "" && e.Pos != "-" {
+		d.File = strings.Join(parts, ":")
+	}
+
+	return d
+}
+
+// PackageDirs returns sorted directories of loaded packages and their dependencies
+func (r *PackagesAnalyzer) PackageDirs() []string {
+	dirs := make([]string, 0)
+	for filename := range r.files {
+		dirs = append(dirs, filepath.Dir(filename))
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+func (r *PackagesAnalyzer) AnalyzeFile(pkg *packages.Package, file *ast.File, fileName string) {
+	for _, decl := range file.Decls {
+		switch node := decl.(type) {
+		// Find controllers
+		case *ast.GenDecl:
+			for _, s := range node.Specs {
+				ts, ok := s.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+
+				obj := pkg.TypesInfo.Defs[ts.Name]
+				if obj == nil {
+					continue
+				}
+
+				if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+					continue
+				}
+
+				if ts.TypeParams != nil && len(ParseAnnotations(node.Doc)["@Controller"]) > 0 {
+					r.errorf(ts.Name.Pos(), "generic controller %s is not supported", ts.Name.Name)
+					continue
+				}
+
+				r.addController(node.Doc, ts.Name, pkg.PkgPath, fileName)
+			}
+		// Find declared resources
+		case *ast.FuncDecl:
+			annotations := ParseAnnotations(node.Doc)
+			method, pathPattern, ok := r.resourceAnnotation(node, annotations)
+			if !ok {
+				continue
+			}
+
+			fn, ok := pkg.TypesInfo.Defs[node.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+
+			sig := fn.Type().(*types.Signature)
+			controllerName := receiverTypeName(sig.Recv().Type())
+			if controllerName == "" {
+				r.errorf(node.Name.Pos(), "cannot resolve controller of resource %s", node.Name.Name)
+				continue
+			}
+
+			params, ok := r.resourceParams(sig)
+			if !ok {
+				continue
+			}
+
+			r.addResource(node, annotations, controllerName, pkg.PkgPath, fileName, method, pathPattern, params)
+		}
+	}
+}
+
+// receiverTypeName returns name of the method receiver named type
+func receiverTypeName(t types.Type) string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+
+	if named, ok := types.Unalias(t).(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// resourceParams resolves types of resource params and classifies them with resourceParam
+func (r *PackagesAnalyzer) resourceParams(sig *types.Signature) ([]Parameter, bool) {
+	params := make([]Parameter, 0)
+
+	for i := 0; i < sig.Params().Len(); i++ {
+		v := sig.Params().At(i)
+		if v.Name() == "" {
+			continue
+		}
+
+		identifier, err := typeIdentifier(v.Type())
+		if err != nil {
+			r.errorf(v.Pos(), "cannot resolve type of param %s: %s", v.Name(), err)
+			return nil, false
+		}
+
+		param, ok := r.resourceParam(v.Name(), identifier, v.Pos(), &typesParamType{r: r, t: v.Type(), pos: v.Pos()})
+		if !ok {
+			return nil, false
+		}
+		params = append(params, param)
+	}
+
+	return params, true
+}
+
+// typesParamType resolves resource param types with go/types
+type typesParamType struct {
+	r     *PackagesAnalyzer
+	t     types.Type
+	pos   token.Pos
+	named *types.Named
+}
+
+func (t *typesParamType) register() {
+	t.r.registerType(t.t, t.pos)
+}
+
+func (t *typesParamType) base() (paramTypeKind, error) {
+	named, ok := baseType(t.t).(*types.Named)
+	if !ok {
+		return 0, fmt.Errorf("unsupported type %s", t.t)
+	}
+	t.named = named
+
+	switch named.Underlying().(type) {
+	case *types.Struct:
+		return paramTypeStruct, nil
+	case *types.Interface:
+		return paramTypeInterface, nil
+	}
+	return paramTypeOther, nil
+}
+
+func (t *typesParamType) addBase() {
+	t.r.registerType(t.named, t.pos)
+}
+
+func (t *typesParamType) responder() Responder {
+	return t.r.parseResponder(t.named)
+}
+
+// baseType strips aliases, pointers, slices and arrays
+func baseType(t types.Type) types.Type {
+	for {
+		switch c := types.Unalias(t).(type) {
+		case *types.Pointer:
+			t = c.Elem()
+		case *types.Slice:
+			t = c.Elem()
+		case *types.Array:
+			t = c.Elem()
+		default:
+			return c
+		}
+	}
+}
+
+// typeIdentifier formats type as type identifier, e.g. []full/path/to/package TypeName,
+// instantiated generic types must be declared as named types to be referenced
+func typeIdentifier(t types.Type) (string, error) {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		if t.Kind() == types.Invalid {
+			return "", fmt.Errorf("type is not defined or has errors")
+		}
+		if t.Kind() == types.UnsafePointer || t.Info()&types.IsUntyped != 0 {
+			return "", fmt.Errorf("unsupported type %s", t)
+		}
+		return t.Name(), nil
+	case *types.Named:
+		if t.TypeArgs().Len() > 0 {
+			return "", fmt.Errorf("generic type %s is not supported, declare named type with it as underlying type", t)
+		}
+		if t.Obj().Pkg() == nil {
+			// error and comparable
+			return t.Obj().Name(), nil
+		}
+		return t.Obj().Pkg().Path() + " " + t.Obj().Name(), nil
+	case *types.Pointer:
+		elem, err := typeIdentifier(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "*" + elem, nil
+	case *types.Slice:
+		elem, err := typeIdentifier(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *types.Array:
+		elem, err := typeIdentifier(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "[" + strconv.FormatInt(t.Len(), 10) + "]" + elem, nil
+	case *types.Map:
+		key, err := typeIdentifier(t.Key())
+		if err != nil {
+			return "", err
+		}
+
+		value, err := typeIdentifier(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "map[" + key + "]" + value, nil
+	case *types.Interface:
+		if t.Empty() {
+			return "any", nil
+		}
+		return "", fmt.Errorf("anonymous interfaces are not supported")
+	case *types.TypeParam:
+		return "", fmt.Errorf("type parameter %s is not supported", t)
+	}
+
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+func (r *PackagesAnalyzer) parseResponder(named *types.Named) Responder {
+	name := named.Obj().Name()
+	responses := make([]Response, 0)
+
+	for _, m := range interfaceMethods(named.Underlying().(*types.Interface)) {
+		sig := m.Type().(*types.Signature)
+
+		params := make([]Parameter, 0)
+		for i := 0; i < sig.Params().Len(); i++ {
+			v := sig.Params().At(i)
+			if v.Name() == "" {
+				continue
+			}
+
+			identifier, err := typeIdentifier(v.Type())
+			if err != nil {
+				r.errorf(v.Pos(), "cannot resolve type of responder %s param %s: %s", name, v.Name(), err)
+				continue
+			}
+			r.registerType(v.Type(), v.Pos())
+
+			params = append(params, Parameter{
+				Type:     identifier,
+				Name:     v.Name(),
+				Position: r.position(v.Pos()),
+			})
+		}
+
+		responses = append(responses, Response{
+			Name:        m.Name(),
+			Annotations: ParseAnnotations(r.methodDoc(m.Pos())),
+			Params:      params,
+			Position:    r.position(m.Pos()),
+		})
+	}
+
+	return Responder{
+		Name:      name,
+		Responses: responses,
+		Position:  r.position(named.Obj().Pos()),
+	}
+}
+
+// interfaceMethods returns declared methods in source order followed by methods of embedded interfaces
+func interfaceMethods(iface *types.Interface) []*types.Func {
+	methods := make([]*types.Func, 0, iface.NumMethods())
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		methods = append(methods, iface.ExplicitMethod(i))
+	}
+	slices.SortFunc(methods, func(a, b *types.Func) int {
+		return int(a.Pos() - b.Pos())
+	})
+
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		embedded, ok := iface.EmbeddedType(i).Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+
+		for _, m := range interfaceMethods(embedded) {
+			if !slices.ContainsFunc(methods, func(f *types.Func) bool { return f.Name() == m.Name() }) {
+				methods = append(methods, m)
+			}
+		}
+	}
+
+	return methods
+}
+
+// methodDoc returns doc comment of the interface method declared at the position
+func (r *PackagesAnalyzer) methodDoc(pos token.Pos) *ast.CommentGroup {
+	filename := r.fset.Position(pos).Filename
+
+	if file, ok := r.files[filename]; ok && !r.indexed[filename] {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if field, ok := n.(*ast.Field); ok && field.Doc != nil {
+				for _, name := range field.Names {
+					r.docs[name.Pos()] = field.Doc
+				}
+			}
+			return true
+		})
+		r.indexed[filename] = true
+	}
+
+	return r.docs[pos]
+}
+
+// registerType adds schemas of named types, primitive and well known types are skipped,
+// composite types register their elements
+func (r *PackagesAnalyzer) registerType(t types.Type, pos token.Pos) {
+	switch t := types.Unalias(t).(type) {
+	case *types.Pointer:
+		r.registerType(t.Elem(), pos)
+	case *types.Slice:
+		r.registerType(t.Elem(), pos)
+	case *types.Array:
+		r.registerType(t.Elem(), pos)
+	case *types.Map:
+		r.registerType(t.Key(), pos)
+		r.registerType(t.Elem(), pos)
+	case *types.Named:
+		identifier, err := typeIdentifier(t)
+		if err != nil {
+			r.errorf(pos, "cannot resolve type %s: %s", t, err)
+			return
+		}
+
+		if t.Obj().Pkg() == nil || WellKnownSchema(identifier) != nil {
+			return
+		}
+
+		r.addType(identifier, t)
+	}
+}
+
+// addType builds schema of the named type underlying type and adds it into definitions if it is not added yet
+func (r *PackagesAnalyzer) addType(identifier string, named *types.Named) {
+	r.addTypeSchema(identifier, named.Obj().Name(), func() TypeSchema {
+		ts := TypeSchema{
+			Name:     named.Obj().Name(),
+			Position: r.position(named.Obj().Pos()),
+			Schema:   r.schema(named.Obj().Name(), named.Underlying(), named.Obj().Pos()),
+		}
+		if basic, ok := named.Underlying().(*types.Basic); ok {
+			// byte and rune are reported by the kind name
+			ts.Underlying = BasicTypeName(types.Typ[basic.Kind()].Name())
+		}
+		return ts
+	})
+}
+
+// isEmbeddedStruct reports whether the embedded field type is a struct or a pointer to a struct,
+// well known types are encoded as values and they are not structs for the schema
+func isEmbeddedStruct(t types.Type) bool {
+	if pointer, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	if named, ok := types.Unalias(t).(*types.Named); ok {
+		if identifier, err := typeIdentifier(named); err == nil && WellKnownSchema(identifier) != nil {
+			return false
+		}
+	}
+
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// schema builds schema of the type, structs are built with NewStructSchema and named types are referenced
+func (r *PackagesAnalyzer) schema(typeName string, t types.Type, pos token.Pos) *Schema {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		if s := PrimitiveSchema(t.Name()); s != nil {
+			return s
+		}
+	case *types.Named:
+		identifier, err := typeIdentifier(t)
+		if err != nil {
+			r.errorf(pos, "cannot resolve type of %s field: %s", typeName, err)
+			return &Schema{}
+		}
+		r.registerType(t, pos)
+		return NewSchemaFromIdentifier(identifier)
+	case *types.Pointer:
+		s := r.schema(typeName, t.Elem(), pos)
+		s.Nullable = true
+		return s
+	case *types.Struct:
+		fields := make([]StructField, 0, t.NumFields())
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			fields = append(fields, StructField{
+				Name:     field.Name(),
+				Exported: field.Exported(),
+				Inline:   field.Embedded() && isEmbeddedStruct(field.Type()),
+				Tag:      ParseJSONStructTag(t.Tag(i)),
+				Schema: func() *Schema {
+					return r.schema(typeName, field.Type(), field.Pos())
+				},
+			})
+		}
+
+		return NewStructSchema(fields)
+	case *types.Slice:
+		if elem, ok := types.Unalias(t.Elem()).(*types.Basic); ok && elem.Kind() == types.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{
+			Type:  "array",
+			Items: r.schema(typeName, t.Elem(), pos),
+		}
+	case *types.Array:
+		return &Schema{
+			Type:  "array",
+			Items: r.schema(typeName, t.Elem(), pos),
+		}
+	case *types.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: r.schema(typeName, t.Elem(), pos),
+		}
+	}
+
+	return &Schema{}
+}
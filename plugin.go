@@ -0,0 +1,215 @@
+package restc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PluginRequest is written by restc to the generator plugin stdin
+type PluginRequest struct {
+	Definitions Definitions       `json:"definitions"`
+	Options     map[string]string `json:"options,omitempty"`
+	// ModulePath and ModuleRoot describe analyzed module, Output is absolute output directory,
+	// plugins could use them to place files next to analyzed packages
+	ModulePath string `json:"modulePath"`
+	ModuleRoot string `json:"moduleRoot"`
+	Output     string `json:"output"`
+}
+
+// PluginResponse is written by the generator plugin to stdout,
+// DefinitionsVersion is the version of definitions the plugin is built for, it must match the request one
+type PluginResponse struct {
+	DefinitionsVersion int             `json:"definitionsVersion"`
+	Files              []GeneratedFile `json:"files"`
+	Diagnostics        []Diagnostic    `json:"diagnostics,omitempty"`
+}
+
+// GeneratedFile is the plugin output file, Path is slash separated and relative to the output directory
+type GeneratedFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// ErrIncompatiblePlugin is returned when plugin and restc are built for different definitions versions
+var ErrIncompatiblePlugin = errors.New("incompatible plugin, rebuild it against the same restc version")
+
+// DefinitionsVersionOf returns the definitions version, unversioned definitions are of version 1
+func DefinitionsVersionOf(version int) int {
+	if version == 0 {
+		return 1
+	}
+	return version
+}
+
+// RunPlugin writes request to the plugin command stdin and reads its response from stdout,
+// plugin stderr is passed through
+func RunPlugin(cmd *exec.Cmd, request PluginRequest) (PluginResponse, error) {
+	var response PluginResponse
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return response, err
+	}
+
+	stdout := bytes.Buffer{}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+
+	if err := cmd.Run(); err != nil {
+		return response, err
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return response, fmt.Errorf("malformed plugin response: %w", err)
+	}
+
+	if response.DefinitionsVersion != request.Definitions.Version {
+		return response, fmt.Errorf("plugin is built for definitions version %d, restc produces version %d: %w",
+			DefinitionsVersionOf(response.DefinitionsVersion), request.Definitions.Version, ErrIncompatiblePlugin)
+	}
+
+	return response, nil
+}
+
+// ValidateFilePaths checks that response files are relative to the output directory and do not escape it,
+// files outside of it are accepted only within the module root, e.g. gin files placed next to controllers
+func ValidateFilePaths(plugin PluginConfig, moduleRoot string, files []GeneratedFile) error {
+	for _, f := range files {
+		path := filepath.FromSlash(f.Path)
+		if path == "" || filepath.Clean(path) == "." || filepath.IsAbs(path) {
+			return fmt.Errorf("plugin %s file path %q must be relative file path", plugin.DisplayName(), f.Path)
+		}
+
+		target := filepath.Join(plugin.Output, path)
+		if isWithin(plugin.Output, target) || isWithin(moduleRoot, target) {
+			continue
+		}
+
+		return fmt.Errorf("plugin %s file path %q is outside of output directory and module root", plugin.DisplayName(), f.Path)
+	}
+
+	return nil
+}
+
+// isWithin reports whether path is a file under the dir
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// WriteFiles writes files with absolute paths, all files are written into temporary files first
+// and renamed into place only when all of them are written, files with unchanged content are not touched.
+// Written files are returned
+func WriteFiles(files []GeneratedFile) (written []GeneratedFile, err error) {
+	temporary := make([]string, 0, len(files))
+	defer func() {
+		if err != nil {
+			for _, name := range temporary {
+				os.Remove(name)
+			}
+		}
+	}()
+
+	for _, f := range files {
+		if current, err := os.ReadFile(f.Path); err == nil && string(current) == f.Content {
+			continue
+		}
+		written = append(written, f)
+
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			return nil, err
+		}
+
+		tmp, err := os.CreateTemp(filepath.Dir(f.Path), "."+filepath.Base(f.Path)+".*")
+		if err != nil {
+			return nil, err
+		}
+		temporary = append(temporary, tmp.Name())
+
+		_, writeErr := tmp.WriteString(f.Content)
+		if err := errors.Join(writeErr, tmp.Chmod(0644), tmp.Close()); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, f := range written {
+		if err := os.Rename(temporary[i], f.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	return written, nil
+}
+
+// GeneratedHeader starts the first line of Go and TypeScript files generated by bundled plugins
+const GeneratedHeader = "// Code generated with RESTc compiler's "
+
+// OrphanedFiles returns files of the directories starting with GeneratedHeader which are not among generated files,
+// e.g. files of removed controllers. Subdirectories are not inspected
+func OrphanedFiles(dirs []string, files []GeneratedFile) ([]string, error) {
+	generated := make(map[string]bool, len(files))
+	for _, f := range files {
+		generated[f.Path] = true
+	}
+
+	var orphaned []string
+	seen := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			name := filepath.Join(dir, entry.Name())
+			if !entry.Type().IsRegular() || generated[name] {
+				continue
+			}
+
+			ok, err := hasGeneratedHeader(name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				orphaned = append(orphaned, name)
+			}
+		}
+	}
+
+	slices.Sort(orphaned)
+	return orphaned, nil
+}
+
+func hasGeneratedHeader(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(GeneratedHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false, nil
+	}
+	return string(header) == GeneratedHeader, nil
+}
@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/tulinowpavel/restc"
+)
+
+// StringParser returns format of the expression parsing string into (value, error) and format converting
+// the parsed value into asType, parse is empty when conversion could not fail. Path, query and header params
+// of builtin, time and named types over them are supported, named types over builtin types are parsed
+// as their underlying type, packages used by expressions are added to imports
+func StringParser(types map[string]restc.TypeSchema, imports *Imports, asType string) (parse string, convert string, err error) {
+	typeName := imports.TypeName(asType)
+
+	switch asType {
+	case "string":
+		return "", "%s", nil
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		imports.AddStd("strconv")
+		return "strconv.ParseInt(%s, 10, " + bitSize(asType) + ")", numericConvert(asType, "int64"), nil
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		imports.AddStd("strconv")
+		return "strconv.ParseUint(%s, 10, " + bitSize(asType) + ")", numericConvert(asType, "uint64"), nil
+	case "float32", "float64":
+		imports.AddStd("strconv")
+		return "strconv.ParseFloat(%s, " + bitSize(asType) + ")", numericConvert(asType, "float64"), nil
+	case "bool":
+		imports.AddStd("strconv")
+		return "strconv.ParseBool(%s)", "%s", nil
+	case "time Time":
+		imports.AddStd("time")
+		return "time.Parse(time.RFC3339Nano, %s)", "%s", nil
+	case "time Duration":
+		imports.AddStd("time")
+		return "time.ParseDuration(%s)", "%s", nil
+	}
+
+	ts, ok := types[asType]
+	if !ok {
+		return "", "", fmt.Errorf("param type %s is not supported", asType)
+	}
+
+	// named types are converted from the value parsed as their underlying type, so it is range checked
+	if ts.Underlying != "" {
+		parse, _, err := StringParser(types, imports, ts.Underlying)
+		if err != nil {
+			return "", "", fmt.Errorf("param type %s is not supported", asType)
+		}
+		return parse, typeName + "(%s)", nil
+	}
+
+	if ts.Schema != nil && ts.Schema.Type == "string" && ts.Schema.Format == "date-time" {
+		imports.AddStd("time")
+		return "time.Parse(time.RFC3339Nano, %s)", typeName + "(%s)", nil
+	}
+
+	return "", "", fmt.Errorf("param type %s is not supported", asType)
+}
+
+func bitSize(typeName string) string {
+	switch typeName {
+	case "int8", "uint8", "byte":
+		return "8"
+	case "int16", "uint16":
+		return "16"
+	case "int32", "uint32", "rune", "float32":
+		return "32"
+	case "int64", "uint64", "float64":
+		return "64"
+	}
+	// platform dependent int, uint and uintptr
+	return "0"
+}
+
+func numericConvert(typeName, parsedType string) string {
+	if typeName == parsedType {
+		return "%s"
+	}
+	return typeName + "(%s)"
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+)
+
+func TestStringParser(t *testing.T) {
+	types := map[string]restc.TypeSchema{
+		"example.com/app/dto Status":   {Underlying: "string", Schema: &restc.Schema{Type: "string"}},
+		"example.com/app/dto Deadline": {Schema: &restc.Schema{Type: "string", Format: "date-time"}},
+		"example.com/app/dto Priority": {Underlying: "int8", Schema: &restc.Schema{Type: "integer", Format: "int32"}},
+		"example.com/app/dto Level":    {Underlying: "int16", Schema: &restc.Schema{Type: "integer", Format: "int32"}},
+		"example.com/app/dto Weight":   {Underlying: "int32", Schema: &restc.Schema{Type: "integer", Format: "int32"}},
+		"example.com/app/dto Count":    {Underlying: "int64", Schema: &restc.Schema{Type: "integer", Format: "int64"}},
+		"example.com/app/dto Size":     {Underlying: "int", Schema: &restc.Schema{Type: "integer"}},
+		"example.com/app/dto Mask":     {Underlying: "uint8", Schema: &restc.Schema{Type: "integer", Format: "int32"}},
+		"example.com/app/dto Port":     {Underlying: "uint16", Schema: &restc.Schema{Type: "integer", Format: "int32"}},
+		"example.com/app/dto Version":  {Underlying: "uint32", Schema: &restc.Schema{Type: "integer", Format: "int64"}},
+		"example.com/app/dto Offset":   {Underlying: "uint64", Schema: &restc.Schema{Type: "integer", Format: "int64"}},
+		"example.com/app/dto Handle":   {Underlying: "uintptr", Schema: &restc.Schema{Type: "integer"}},
+		"example.com/app/dto Scale":    {Underlying: "float32", Schema: &restc.Schema{Type: "number", Format: "float"}},
+		"example.com/app/dto Ratio":    {Underlying: "float64", Schema: &restc.Schema{Type: "number", Format: "double"}},
+		"example.com/app/dto Flag":     {Underlying: "bool", Schema: &restc.Schema{Type: "boolean"}},
+		"example.com/app/dto Wave":     {Underlying: "complex128", Schema: &restc.Schema{}},
+		"example.com/app/dto Legacy":   {Schema: &restc.Schema{Type: "integer", Format: "int32"}},
+		"example.com/app/dto Label":    {Schema: &restc.Schema{Type: "object"}},
+	}
+
+	tests := []struct {
+		asType  string
+		parse   string
+		convert string
+		std     []string
+		err     bool
+	}{
+		{asType: "string", convert: "%s"},
+		{asType: "int", parse: "strconv.ParseInt(%s, 10, 0)", convert: "int(%s)", std: []string{"strconv"}},
+		{asType: "int8", parse: "strconv.ParseInt(%s, 10, 8)", convert: "int8(%s)", std: []string{"strconv"}},
+		{asType: "int64", parse: "strconv.ParseInt(%s, 10, 64)", convert: "%s", std: []string{"strconv"}},
+		{asType: "rune", parse: "strconv.ParseInt(%s, 10, 32)", convert: "rune(%s)", std: []string{"strconv"}},
+		{asType: "uint32", parse: "strconv.ParseUint(%s, 10, 32)", convert: "uint32(%s)", std: []string{"strconv"}},
+		{asType: "uint64", parse: "strconv.ParseUint(%s, 10, 64)", convert: "%s", std: []string{"strconv"}},
+		{asType: "byte", parse: "strconv.ParseUint(%s, 10, 8)", convert: "byte(%s)", std: []string{"strconv"}},
+		{asType: "float32", parse: "strconv.ParseFloat(%s, 32)", convert: "float32(%s)", std: []string{"strconv"}},
+		{asType: "float64", parse: "strconv.ParseFloat(%s, 64)", convert: "%s", std: []string{"strconv"}},
+		{asType: "bool", parse: "strconv.ParseBool(%s)", convert: "%s", std: []string{"strconv"}},
+		{asType: "time Time", parse: "time.Parse(time.RFC3339Nano, %s)", convert: "%s", std: []string{"time"}},
+		{asType: "time Duration", parse: "time.ParseDuration(%s)", convert: "%s", std: []string{"time"}},
+		{asType: "example.com/app/dto Status", convert: "example_com_app_dto.Status(%s)"},
+		{asType: "example.com/app/dto Deadline", parse: "time.Parse(time.RFC3339Nano, %s)", convert: "example_com_app_dto.Deadline(%s)", std: []string{"time"}},
+		{asType: "example.com/app/dto Priority", parse: "strconv.ParseInt(%s, 10, 8)", convert: "example_com_app_dto.Priority(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Level", parse: "strconv.ParseInt(%s, 10, 16)", convert: "example_com_app_dto.Level(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Weight", parse: "strconv.ParseInt(%s, 10, 32)", convert: "example_com_app_dto.Weight(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Count", parse: "strconv.ParseInt(%s, 10, 64)", convert: "example_com_app_dto.Count(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Size", parse: "strconv.ParseInt(%s, 10, 0)", convert: "example_com_app_dto.Size(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Mask", parse: "strconv.ParseUint(%s, 10, 8)", convert: "example_com_app_dto.Mask(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Port", parse: "strconv.ParseUint(%s, 10, 16)", convert: "example_com_app_dto.Port(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Version", parse: "strconv.ParseUint(%s, 10, 32)", convert: "example_com_app_dto.Version(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Offset", parse: "strconv.ParseUint(%s, 10, 64)", convert: "example_com_app_dto.Offset(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Handle", parse: "strconv.ParseUint(%s, 10, 0)", convert: "example_com_app_dto.Handle(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Scale", parse: "strconv.ParseFloat(%s, 32)", convert: "example_com_app_dto.Scale(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Ratio", parse: "strconv.ParseFloat(%s, 64)", convert: "example_com_app_dto.Ratio(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Flag", parse: "strconv.ParseBool(%s)", convert: "example_com_app_dto.Flag(%s)", std: []string{"strconv"}},
+		{asType: "example.com/app/dto Wave", err: true},
+		{asType: "example.com/app/dto Legacy", err: true},
+		{asType: "example.com/app/dto Label", err: true},
+		{asType: "example.com/app/dto Unknown", err: true},
+		{asType: "complex128", err: true},
+		{asType: "[]string", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.asType, func(t *testing.T) {
+			imports := NewImports("")
+
+			parse, convert, err := StringParser(types, imports, tt.asType)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("error expected, got %q, %q", parse, convert)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parse != tt.parse || convert != tt.convert {
+				t.Errorf("got %q, %q, want %q, %q", parse, convert, tt.parse, tt.convert)
+			}
+			if paths := imports.StdPaths(); !slices.Equal(paths, tt.std) {
+				t.Errorf("std imports %q, want %q", paths, tt.std)
+			}
+		})
+	}
+}
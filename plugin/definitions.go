@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tulinowpavel/restc"
+)
+
+// SortedKeys returns map keys in ascending order, plugins iterate definitions in it to produce stable output
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Controllers returns controllers sorted by name
+func (r *Request) Controllers() []restc.Controller {
+	controllers := make([]restc.Controller, 0, len(r.Definitions.Controllers))
+	for _, name := range SortedKeys(r.Definitions.Controllers) {
+		controllers = append(controllers, r.Definitions.Controllers[name])
+	}
+	return controllers
+}
+
+// Resources returns controller resources sorted by name
+func Resources(controller restc.Controller) []restc.Resource {
+	resources := make([]restc.Resource, 0, len(controller.Resources))
+	for _, name := range SortedKeys(controller.Resources) {
+		resources = append(resources, controller.Resources[name])
+	}
+	return resources
+}
+
+// Responders returns responders sorted by type identifier
+func (r *Request) Responders() []restc.Responder {
+	responders := make([]restc.Responder, 0, len(r.Definitions.Responders))
+	for _, identifier := range SortedKeys(r.Definitions.Responders) {
+		responders = append(responders, r.Definitions.Responders[identifier])
+	}
+	return responders
+}
+
+// Responder returns responder of the resource
+func (r *Request) Responder(resource restc.Resource) (restc.Responder, bool) {
+	for _, param := range resource.Params {
+		if param.Source == restc.ParameterSourceResponder {
+			responder, ok := r.Definitions.Responders[param.Type]
+			return responder, ok
+		}
+	}
+	return restc.Responder{}, false
+}
+
+// ResponseStatus returns @Status annotation of the responder method, 200 is assumed without it
+func ResponseStatus(response restc.Response) string {
+	if statuses := response.Annotations["@Status"]; len(statuses) > 0 {
+		return statuses[0]
+	}
+	return "200"
+}
+
+// ShortNames maps type identifiers (keys of Definitions.Types or Definitions.Responders) to names for generated code,
+// type names are used unless they collide across packages, colliding names are prefixed with the package alias
+// joined with separator, e.g. example_com_app_task.Task for "." separator
+func ShortNames[V any](definitions map[string]V, separator string) map[string]string {
+	counts := make(map[string]int)
+	for identifier := range definitions {
+		_, name, _ := strings.Cut(identifier, " ")
+		counts[name]++
+	}
+
+	names := make(map[string]string, len(definitions))
+	for identifier := range definitions {
+		packagePath, name, ok := strings.Cut(identifier, " ")
+		if !ok {
+			names[identifier] = identifier
+			continue
+		}
+
+		if counts[name] > 1 {
+			name = PackageAlias(packagePath) + separator + name
+		}
+		names[identifier] = name
+	}
+	return names
+}
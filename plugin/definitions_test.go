@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+)
+
+func TestShortNames(t *testing.T) {
+	responders := map[string]restc.Responder{
+		"example.com/app/task GetResponder":  {},
+		"example.com/app/label GetResponder": {},
+		"example.com/app/task ListResponder": {},
+	}
+
+	want := map[string]string{
+		"example.com/app/task GetResponder":  "example_com_app_task_GetResponder",
+		"example.com/app/label GetResponder": "example_com_app_label_GetResponder",
+		"example.com/app/task ListResponder": "ListResponder",
+	}
+
+	if got := ShortNames(responders, "_"); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	types := map[string]restc.TypeSchema{
+		"example.com/app/task Task":    {},
+		"example.com/app/v2/task Task": {},
+	}
+
+	want = map[string]string{
+		"example.com/app/task Task":    "example_com_app_task.Task",
+		"example.com/app/v2/task Task": "example_com_app_v2_task.Task",
+	}
+
+	if got := ShortNames(types, "."); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tulinowpavel/restc"
+)
+
+var packageAliasRegex = regexp.MustCompile(`[\/\.\-]+`)
+
+// PackageAlias returns import alias of the package, the same as analyzer uses for Definitions.Imports,
+// e.g. example_com_app_internal_task for example.com/app/internal/task
+func PackageAlias(packagePath string) string {
+	return strings.ToLower(packageAliasRegex.ReplaceAllString(packagePath, "_"))
+}
+
+// Imports collects packages referenced by generated Go file, only they are imported.
+// Types of the file own package are not qualified
+type Imports struct {
+	Package string
+
+	aliases map[string]string
+	paths   map[string]string
+	std     map[string]bool
+}
+
+func NewImports(packagePath string) *Imports {
+	return &Imports{
+		Package: packagePath,
+		aliases: make(map[string]string),
+		paths:   make(map[string]string),
+		std:     make(map[string]bool),
+	}
+}
+
+// Add imports the package and returns its alias, aliases of different packages are made unique with numeric suffix.
+// Packages imported with AddStd are referenced by their names
+func (i *Imports) Add(packagePath string) string {
+	if alias, ok := i.aliases[packagePath]; ok {
+		return alias
+	}
+
+	if i.std[packagePath] {
+		i.aliases[packagePath] = restc.ImportPathName(packagePath)
+		return i.aliases[packagePath]
+	}
+
+	alias := PackageAlias(packagePath)
+	for n := 2; i.paths[alias] != ""; n++ {
+		alias = PackageAlias(packagePath) + strconv.Itoa(n)
+	}
+
+	i.aliases[packagePath] = alias
+	i.paths[alias] = packagePath
+
+	return alias
+}
+
+// AddStd imports the package without alias and returns its name, e.g. standard library packages used
+// by generated code itself. Types of the package are qualified with the same name unless they were
+// qualified with an alias before
+func (i *Imports) AddStd(packagePath string) string {
+	name := restc.ImportPathName(packagePath)
+
+	i.std[packagePath] = true
+	i.paths[name] = packagePath
+
+	return name
+}
+
+// Qualify returns qualified type name importing its package, it could be passed to restc.FormatTypeIdentifier
+func (i *Imports) Qualify(packagePath, typeName string) string {
+	if packagePath == i.Package {
+		return typeName
+	}
+	return i.Add(packagePath) + "." + typeName
+}
+
+// TypeName converts type identifier into Go type expression importing referenced packages
+func (i *Imports) TypeName(identifier string) string {
+	return restc.FormatTypeIdentifier(identifier, i.Qualify)
+}
+
+// Specs returns sorted import specs of aliased packages, e.g. example_com_app_task "example.com/app/task",
+// packages referenced by their names are returned by StdPaths
+func (i *Imports) Specs() []string {
+	specs := make([]string, 0, len(i.aliases))
+	for packagePath, alias := range i.aliases {
+		if i.std[packagePath] && alias == restc.ImportPathName(packagePath) {
+			continue
+		}
+		specs = append(specs, alias+` "`+packagePath+`"`)
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// StdPaths returns sorted paths of packages imported without alias
+func (i *Imports) StdPaths() []string {
+	return SortedKeys(i.std)
+}
@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestImports(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdLast  bool
+		std      []string
+		types    []string
+		want     []string
+		specs    []string
+		stdPaths []string
+	}{
+		{
+			name:  "own package is not qualified",
+			types: []string{"example.com/app/task Task", "[]*example.com/app/dto Label"},
+			want:  []string{"Task", "[]*example_com_app_dto.Label"},
+			specs: []string{`example_com_app_dto "example.com/app/dto"`},
+		},
+		{
+			name:     "std package added before type",
+			std:      []string{"time", "strconv"},
+			types:    []string{"time Time", "*time Duration"},
+			want:     []string{"time.Time", "*time.Duration"},
+			stdPaths: []string{"strconv", "time"},
+		},
+		{
+			name:     "std package added after type",
+			stdLast:  true,
+			types:    []string{"time Time"},
+			std:      []string{"time"},
+			want:     []string{"time.Time"},
+			stdPaths: []string{"time"},
+		},
+		{
+			name:     "std package with path",
+			std:      []string{"net/http"},
+			types:    []string{"net/http Header"},
+			want:     []string{"http.Header"},
+			stdPaths: []string{"net/http"},
+		},
+		{
+			name:     "aliased before std import",
+			stdLast:  true,
+			types:    []string{"net/http Header"},
+			std:      []string{"net/http"},
+			want:     []string{"net_http.Header"},
+			specs:    []string{`net_http "net/http"`},
+			stdPaths: []string{"net/http"},
+		},
+		{
+			name:  "colliding aliases",
+			types: []string{"example.com/app/task Task", "example.com/app_task Task", "example.com/app-task Task"},
+			want:  []string{"Task", "example_com_app_task.Task", "example_com_app_task2.Task"},
+			specs: []string{`example_com_app_task "example.com/app_task"`, `example_com_app_task2 "example.com/app-task"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imports := NewImports("example.com/app/task")
+
+			addStd := func() {
+				for _, packagePath := range tt.std {
+					imports.AddStd(packagePath)
+				}
+			}
+
+			if !tt.stdLast {
+				addStd()
+			}
+
+			var got []string
+			for _, identifier := range tt.types {
+				got = append(got, imports.TypeName(identifier))
+			}
+
+			if tt.stdLast {
+				addStd()
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("type names %q, want %q", got, tt.want)
+			}
+			if specs := imports.Specs(); !slices.Equal(specs, tt.specs) {
+				t.Errorf("specs %q, want %q", specs, tt.specs)
+			}
+			if paths := imports.StdPaths(); !slices.Equal(paths, tt.stdPaths) {
+				t.Errorf("std paths %q, want %q", paths, tt.stdPaths)
+			}
+		})
+	}
+}
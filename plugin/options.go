@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Option returns plugin option or default value
+func (r *Request) Option(name, defaultValue string) string {
+	if v := r.Options[name]; v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// OptionEnv returns plugin option, environment variable or default value
+func (r *Request) OptionEnv(name, env, defaultValue string) string {
+	if v := r.Options[name]; v != "" {
+		return v
+	}
+	if v := os.Getenv(env); env != "" && v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// BoolOption parses plugin option with strconv.ParseBool
+func (r *Request) BoolOption(name string, defaultValue bool) (bool, error) {
+	v := r.Options[name]
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("option %s must be a boolean, got %q", name, v)
+	}
+	return b, nil
+}
+
+// EnumOption returns plugin option which must be one of allowed values, the first value is the default
+func (r *Request) EnumOption(name string, allowed ...string) (string, error) {
+	v := r.Option(name, allowed[0])
+	if !slices.Contains(allowed, v) {
+		return "", fmt.Errorf("option %s must be one of %s, got %q", name, strings.Join(allowed, ", "), v)
+	}
+	return v, nil
+}
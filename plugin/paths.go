@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tulinowpavel/restc"
+)
+
+var duplicateSlashRegex = regexp.MustCompile(`/{2,}`)
+
+// NormalizePath collapses duplicate slashes of the joined controller base and resource path
+func NormalizePath(resourcePath string) string {
+	return duplicateSlashRegex.ReplaceAllString(resourcePath, "/")
+}
+
+// FullPath returns normalized resource path prefixed with controller base path
+func FullPath(controller restc.Controller, resource restc.Resource) string {
+	return NormalizePath(controller.Base + resource.Path)
+}
+
+var pathParamRegex = regexp.MustCompile(`\{([[:alnum:]]*)\}`)
+
+// PathParams returns names of {param} placeholders in the order of appearance
+func PathParams(resourcePath string) []string {
+	params := make([]string, 0)
+	for _, m := range pathParamRegex.FindAllStringSubmatch(resourcePath, -1) {
+		params = append(params, m[1])
+	}
+	return params
+}
+
+// ReplacePathParams replaces {param} placeholders, e.g. with :param for gin routes
+func ReplacePathParams(resourcePath string, replace func(name string) string) string {
+	return pathParamRegex.ReplaceAllStringFunc(resourcePath, func(s string) string {
+		return replace(s[1 : len(s)-1])
+	})
+}
+
+// PathSegment is either Literal part of the path or Param placeholder name
+type PathSegment struct {
+	Literal string
+	Param   string
+}
+
+// SplitPath splits path into literals and params, e.g. to build path with escaped param values
+func SplitPath(resourcePath string) []PathSegment {
+	segments := make([]PathSegment, 0)
+	last := 0
+	for _, m := range pathParamRegex.FindAllStringSubmatchIndex(resourcePath, -1) {
+		if m[0] > last {
+			segments = append(segments, PathSegment{Literal: resourcePath[last:m[0]]})
+		}
+		segments = append(segments, PathSegment{Param: resourcePath[m[2]:m[3]]})
+		last = m[1]
+	}
+	if last < len(resourcePath) {
+		segments = append(segments, PathSegment{Literal: resourcePath[last:]})
+	}
+	return segments
+}
+
+// PackageDir returns absolute directory of the analyzed module package
+func (r *Request) PackageDir(packagePath string) string {
+	return filepath.Join(r.ModuleRoot, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(packagePath, r.ModulePath), "/")))
+}
+
+// SourceFile returns absolute path of the resource source file for diagnostics
+func (r *Request) SourceFile(resource restc.Resource) string {
+	return filepath.Join(r.PackageDir(resource.Package), resource.File)
+}
+
+// AbsolutePosition resolves position file against the module root
+func (r *Request) AbsolutePosition(pos restc.Position) restc.Position {
+	if !filepath.IsAbs(filepath.FromSlash(pos.File)) {
+		pos.File = filepath.Join(r.ModuleRoot, filepath.FromSlash(pos.File))
+	}
+	return pos
+}
+
+// LineDirective returns //line comment attributing following generated code to the definition source,
+// it must start at the beginning of the line
+func (r *Request) LineDirective(pos *restc.Position) string {
+	if pos == nil {
+		return ""
+	}
+	return "//line " + r.AbsolutePosition(*pos).String()
+}
+
+// OutputPath converts absolute path into slash separated path relative to the output directory
+func (r *Request) OutputPath(absolutePath string) (string, error) {
+	rel, err := filepath.Rel(r.Output, absolutePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,141 @@
+// Package plugin helps to write restc generator plugins:
+//
+//	func main() {
+//		plugin.Run(func(request *plugin.Request) (*plugin.Response, error) {
+//			response := &plugin.Response{}
+//			for _, controller := range request.Controllers() {
+//				...
+//			}
+//			return response, response.AddGoFile(request.Option("file", "gen.go"), source)
+//		})
+//	}
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+
+	"github.com/tulinowpavel/restc"
+)
+
+// Request is the plugin request read from stdin
+type Request struct {
+	restc.PluginRequest
+}
+
+// Response collects generated files and diagnostics, file paths are slash separated and relative to the output directory
+type Response struct {
+	Files       []restc.GeneratedFile
+	Diagnostics []restc.Diagnostic
+}
+
+// Run reads request from stdin, invokes generate and writes its response to stdout,
+// error returned by generate is reported to restc as error diagnostic
+func Run(generate func(*Request) (*Response, error)) {
+	request, err := ReadRequest(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	response, err := generate(request)
+	if response == nil {
+		response = &Response{}
+	}
+	if err != nil {
+		response.Errorf("", "%s", err)
+	}
+
+	if err := response.Write(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func ReadRequest(r io.Reader) (*Request, error) {
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read plugin request: %w", err)
+	}
+
+	var request Request
+	if err := json.Unmarshal(payload, &request.PluginRequest); err != nil {
+		return nil, fmt.Errorf("malformed plugin request: %w", err)
+	}
+
+	if version := restc.DefinitionsVersionOf(request.Definitions.Version); version != restc.DefinitionsVersion {
+		return nil, fmt.Errorf("restc produces definitions version %d, plugin is built for version %d: %w",
+			version, restc.DefinitionsVersion, restc.ErrIncompatiblePlugin)
+	}
+
+	return &request, nil
+}
+
+// Write encodes response as restc.PluginResponse, files are not written when there are errors
+func (r *Response) Write(w io.Writer) error {
+	response := restc.PluginResponse{
+		DefinitionsVersion: restc.DefinitionsVersion,
+		Diagnostics:        r.Diagnostics,
+	}
+	if !r.HasErrors() {
+		response.Files = r.Files
+	}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+func (r *Response) AddFile(path, content string) {
+	r.Files = append(r.Files, restc.GeneratedFile{Path: path, Content: content})
+}
+
+// AddGoFile formats Go source and adds it into response
+func (r *Response) AddGoFile(path, source string) error {
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return fmt.Errorf("cannot format %s: %w", path, err)
+	}
+
+	r.AddFile(path, string(formatted))
+
+	return nil
+}
+
+func (r *Response) Errorf(file, format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, restc.Diagnostic{
+		Severity: restc.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+	})
+}
+
+func (r *Response) Warnf(file, format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, restc.Diagnostic{
+		Severity: restc.SeverityWarning,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+	})
+}
+
+// ErrorfAt reports error pointing at the definition source, position could be nil for definitions without it
+func (r *Response) ErrorfAt(pos *restc.Position, format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, positionDiagnostic(restc.SeverityError, pos, fmt.Sprintf(format, args...)))
+}
+
+func (r *Response) WarnfAt(pos *restc.Position, format string, args ...any) {
+	r.Diagnostics = append(r.Diagnostics, positionDiagnostic(restc.SeverityWarning, pos, fmt.Sprintf(format, args...)))
+}
+
+func positionDiagnostic(severity restc.Severity, pos *restc.Position, message string) restc.Diagnostic {
+	d := restc.Diagnostic{Severity: severity, Message: message}
+	if pos != nil {
+		d.File, d.Line, d.Column = pos.File, pos.Line, pos.Column
+	}
+	return d
+}
+
+func (r *Response) HasErrors() bool {
+	return restc.HasErrors(r.Diagnostics)
+}
@@ -0,0 +1,76 @@
+package restc
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateFilePaths(t *testing.T) {
+	root := filepath.FromSlash("/src/app")
+	plugin := PluginConfig{Name: "gin", Output: filepath.Join(root, "internal", "server")}
+
+	tests := []struct {
+		path string
+		err  bool
+	}{
+		{path: "gin_gen.go"},
+		{path: "v1/gin_gen.go"},
+		{path: "./gin_gen.go"},
+		{path: "../task/task_controller_gen.go"},
+		{path: "../../go_gen.go"},
+		{path: "", err: true},
+		{path: ".", err: true},
+		{path: "/etc/x", err: true},
+		{path: "../../../x.go", err: true},
+		{path: "../../../../etc/x", err: true},
+		{path: "v1/../../../../app2/x.go", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := ValidateFilePaths(plugin, root, []GeneratedFile{{Path: tt.path}})
+			if (err != nil) != tt.err {
+				t.Errorf("got error %v, error expected %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestValidateFilePathsOutsideModule(t *testing.T) {
+	// output directory outside of the module root, e.g. client of the sibling project
+	plugin := PluginConfig{Command: "./gen", Output: filepath.FromSlash("/src/web/api")}
+
+	if err := ValidateFilePaths(plugin, filepath.FromSlash("/src/app"), []GeneratedFile{{Path: "client.ts"}}); err != nil {
+		t.Error(err)
+	}
+
+	err := ValidateFilePaths(plugin, filepath.FromSlash("/src/app"), []GeneratedFile{{Path: "../x.ts"}})
+	if err == nil || err.Error() != `plugin ./gen file path "../x.ts" is outside of output directory and module root` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestOrphanedFiles(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "other")
+
+	writeTestFile(t, filepath.Join(dir, "gin_gen.go"), GeneratedHeader+"gin plugin DO NOT EDIT.\n")
+	writeTestFile(t, filepath.Join(dir, "removed_gen.go"), GeneratedHeader+"gin plugin DO NOT EDIT.\n")
+	writeTestFile(t, filepath.Join(dir, "mock_gen.go"), "// Code generated by MockGen. DO NOT EDIT.\n")
+	writeTestFile(t, filepath.Join(dir, "handler.go"), "package server\n")
+	writeTestFile(t, filepath.Join(dir, "empty.go"), "")
+	writeTestFile(t, filepath.Join(dir, "nested", "old_gen.go"), GeneratedHeader+"gin plugin DO NOT EDIT.\n")
+	writeTestFile(t, filepath.Join(other, "client.ts"), GeneratedHeader+"typescript plugin DO NOT EDIT.\n")
+
+	files := []GeneratedFile{{Path: filepath.Join(dir, "gin_gen.go")}}
+	got, err := OrphanedFiles([]string{dir, dir, other, filepath.Join(dir, "missing")}, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(dir, "other", "client.ts"), filepath.Join(dir, "removed_gen.go")}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+// generator holds state of a single plugin request
+type generator struct {
+	request     *plugin.Request
+	definitions restc.Definitions
+
+	// response collects the report file and diagnostics
+	response *plugin.Response
+}
+
+func main() {
+	plugin.Run(generate)
+}
+
+func generate(request *plugin.Request) (*plugin.Response, error) {
+	g := &generator{
+		request:     request,
+		definitions: request.Definitions,
+		response:    &plugin.Response{},
+	}
+
+	sb := strings.Builder{}
+	g.WriteRoutes(&sb)
+	g.WriteResponders(&sb)
+	g.WriteTypes(&sb)
+	g.CheckTypeReferences()
+
+	// report is printed to stderr unless it is requested as a file
+	if file := request.Option("file", ""); file != "" {
+		g.response.AddFile(file, sb.String())
+	} else {
+		os.Stderr.WriteString(sb.String())
+	}
+
+	return g.response, nil
+}
+
+// Route is the resource with its full path
+type Route struct {
+	Method     string
+	Path       string
+	Controller restc.Controller
+	Resource   restc.Resource
+}
+
+// WriteRoutes writes route table sorted by path and method and checks resource params
+func (g *generator) WriteRoutes(sb *strings.Builder) {
+	routes := make([]Route, 0)
+	for _, controller := range g.request.Controllers() {
+		for _, resource := range plugin.Resources(controller) {
+			routes = append(routes, Route{
+				Method:     strings.ToUpper(resource.Method),
+				Path:       plugin.FullPath(controller, resource),
+				Controller: controller,
+				Resource:   resource,
+			})
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	sb.WriteString(fmt.Sprintf("Routes (%d)\n\n", len(routes)))
+
+	tw := tabwriter.NewWriter(sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tPARAMS\tRESPONSES")
+
+	seen := make(map[string]Route)
+	for _, route := range routes {
+		handler := route.Controller.Name + "." + route.Resource.Name
+
+		params := make([]string, 0, len(route.Resource.Params))
+		for _, param := range route.Resource.Params {
+			params = append(params, FormatParam(param))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, handler, strings.Join(params, ", "), strings.Join(g.ResponseStatuses(route.Resource), ", "))
+
+		key := route.Method + " " + route.Path
+		if other, ok := seen[key]; ok {
+			g.warnf(route.Resource, "route %s of %s is already handled by %s.%s", key, handler, other.Controller.Name, other.Resource.Name)
+		}
+		seen[key] = route
+
+		g.CheckParams(route, handler)
+	}
+
+	if err := tw.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+// FormatParam returns param name with its source and metadata, e.g. organizationID Header X-Organization-Id
+func FormatParam(param restc.Parameter) string {
+	s := param.Name + " " + string(param.Source)
+	if param.Source == restc.ParameterSourceResponder {
+		s += " " + restc.FormatTypeIdentifier(param.Type, ShortQualifier)
+	}
+	if param.Metadata != "" {
+		s += " " + param.Metadata
+	}
+	return s
+}
+
+// ResponseStatuses returns statuses and methods of the resource responder, e.g. 200 Created
+func (g *generator) ResponseStatuses(resource restc.Resource) []string {
+	statuses := make([]string, 0)
+	responder, _ := g.request.Responder(resource)
+	for _, response := range responder.Responses {
+		statuses = append(statuses, plugin.ResponseStatus(response)+" "+response.Name)
+	}
+	return statuses
+}
+
+// CheckParams reports path params without arguments, path arguments missing in the path,
+// multiple bodies and resources without responder
+func (g *generator) CheckParams(route Route, handler string) {
+	placeholders := make(map[string]bool)
+	for _, name := range plugin.PathParams(route.Path) {
+		placeholders[name] = true
+	}
+
+	bodies := 0
+	responders := 0
+	pathParams := make(map[string]bool)
+	for _, param := range route.Resource.Params {
+		switch param.Source {
+		case restc.ParameterSourcePath:
+			pathParams[param.Name] = true
+			if !placeholders[param.Name] {
+				g.response.WarnfAt(param.Position, "path param %s of %s is missing in path %s", param.Name, handler, route.Path)
+			}
+		case restc.ParameterSourceBody:
+			bodies++
+		case restc.ParameterSourceResponder:
+			responders++
+		}
+	}
+
+	for _, name := range plugin.SortedKeys(placeholders) {
+		if !pathParams[name] {
+			g.warnf(route.Resource, "path %s of %s has param {%s} without matching argument", route.Path, handler, name)
+		}
+	}
+
+	if bodies > 1 {
+		g.warnf(route.Resource, "%s has %d body params, only one request body could be decoded", handler, bodies)
+	}
+
+	if responders == 0 {
+		g.warnf(route.Resource, "%s has no responder param, handler could not write a response", handler)
+	}
+}
+
+// WriteResponders writes responder methods with statuses and params
+func (g *generator) WriteResponders(sb *strings.Builder) {
+	sb.WriteString(fmt.Sprintf("\nResponders (%d)\n", len(g.definitions.Responders)))
+
+	for _, name := range plugin.SortedKeys(g.definitions.Responders) {
+		responder := g.definitions.Responders[name]
+
+		sb.WriteString("\n" + restc.FormatTypeIdentifier(name, ShortQualifier) + "\n")
+
+		tw := tabwriter.NewWriter(sb, 0, 4, 2, ' ', 0)
+		statuses := make(map[string]string)
+		for _, r := range responder.Responses {
+			status := plugin.ResponseStatus(r)
+
+			params := make([]string, 0, len(r.Params))
+			for _, param := range r.Params {
+				params = append(params, param.Name+" "+restc.FormatTypeIdentifier(param.Type, ShortQualifier))
+			}
+
+			fmt.Fprintf(tw, "  %s\t%s(%s)\n", status, r.Name, strings.Join(params, ", "))
+
+			if _, ok := r.Annotations["@Status"]; !ok {
+				g.response.WarnfAt(r.Position, "responder %s method %s has no @Status annotation, 200 is assumed", responder.Name, r.Name)
+			}
+
+			if other, ok := statuses[status]; ok {
+				g.response.WarnfAt(r.Position, "responder %s methods %s and %s have the same status %s", responder.Name, other, r.Name, status)
+			}
+			statuses[status] = r.Name
+		}
+
+		if err := tw.Flush(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// WriteTypes writes registered types with their JSON schema types
+func (g *generator) WriteTypes(sb *strings.Builder) {
+	sb.WriteString(fmt.Sprintf("\nTypes (%d)\n\n", len(g.definitions.Types)))
+
+	tw := tabwriter.NewWriter(sb, 0, 4, 2, ' ', 0)
+	for _, name := range plugin.SortedKeys(g.definitions.Types) {
+		t := g.definitions.Types[name]
+
+		kind := "-"
+		if t.Schema != nil {
+			kind = SchemaKind(t.Schema)
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\n", name, kind)
+	}
+
+	if err := tw.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+// SchemaKind returns short description of the schema, e.g. object{3}, array of string or $ref
+func SchemaKind(s *restc.Schema) string {
+	switch {
+	case s.Ref != "":
+		return restc.FormatTypeIdentifier(s.Ref, ShortQualifier)
+	case s.Type == "object" && s.Properties != nil:
+		return fmt.Sprintf("object{%d}", s.Properties.Len())
+	case s.Type == "array" && s.Items != nil:
+		return "array of " + SchemaKind(s.Items)
+	case len(s.AllOf) > 0:
+		return fmt.Sprintf("allOf{%d}", len(s.AllOf))
+	case s.Type == "":
+		return "any"
+	case s.Format != "":
+		return s.Type + " (" + s.Format + ")"
+	}
+	return s.Type
+}
+
+// CheckTypeReferences reports schema references to types missing in definitions
+func (g *generator) CheckTypeReferences() {
+	for _, name := range plugin.SortedKeys(g.definitions.Types) {
+		if schema := g.definitions.Types[name].Schema; schema != nil {
+			g.checkSchemaReferences(name, g.definitions.Types[name].Position, schema)
+		}
+	}
+}
+
+func (g *generator) checkSchemaReferences(typeName string, pos *restc.Position, s *restc.Schema) {
+	if s.Ref != "" {
+		if _, ok := g.definitions.Types[s.Ref]; !ok {
+			g.response.WarnfAt(pos, "type %s references unknown type %s", typeName, s.Ref)
+		}
+	}
+
+	if s.Items != nil {
+		g.checkSchemaReferences(typeName, pos, s.Items)
+	}
+	if s.AdditionalProperties != nil {
+		g.checkSchemaReferences(typeName, pos, s.AdditionalProperties)
+	}
+	if s.Properties != nil {
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			g.checkSchemaReferences(typeName, pos, pair.Value)
+		}
+	}
+	for _, embedded := range s.AllOf {
+		g.checkSchemaReferences(typeName, pos, embedded)
+	}
+}
+
+// warnf adds warning pointing to the resource
+func (g *generator) warnf(resource restc.Resource, format string, args ...any) {
+	g.response.WarnfAt(resource.Position, format, args...)
+}
+
+// ShortQualifier qualifies types with the last element of the package path
+func ShortQualifier(packagePath, typeName string) string {
+	return path.Base(packagePath) + "." + typeName
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+func TestGenerateChecks(t *testing.T) {
+	responder := restc.Parameter{Source: restc.ParameterSourceResponder, Name: "r", Type: "example.com/app/task TaskResponder"}
+	pos := func(line int) *restc.Position {
+		return &restc.Position{File: "task/task.go", Line: line, Column: 2}
+	}
+
+	tests := []struct {
+		name       string
+		resources  []restc.Resource
+		responses  []restc.Response
+		types      map[string]restc.TypeSchema
+		wantChecks []string
+	}{
+		{
+			name: "valid",
+			resources: []restc.Resource{
+				{Name: "Get", Method: "GET", Path: "/{id}", Position: pos(10), Params: []restc.Parameter{
+					responder,
+					{Source: restc.ParameterSourcePath, Name: "id", Type: "int64", Position: pos(10)},
+				}},
+			},
+		},
+		{
+			name: "path param missing in path",
+			resources: []restc.Resource{
+				{Name: "Get", Method: "GET", Path: "/", Position: pos(10), Params: []restc.Parameter{
+					responder,
+					{Source: restc.ParameterSourcePath, Name: "id", Type: "int64", Position: pos(11)},
+				}},
+			},
+			wantChecks: []string{"task/task.go:11:2: path param id of TaskController.Get is missing in path /tasks/"},
+		},
+		{
+			name: "placeholder without argument",
+			resources: []restc.Resource{
+				{Name: "Get", Method: "GET", Path: "/{id}/labels/{label}", Position: pos(10), Params: []restc.Parameter{
+					responder,
+					{Source: restc.ParameterSourcePath, Name: "id", Type: "int64", Position: pos(10)},
+				}},
+			},
+			wantChecks: []string{"task/task.go:10:2: path /tasks/{id}/labels/{label} of TaskController.Get has param {label} without matching argument"},
+		},
+		{
+			name: "braces are not placeholders unless generators route them",
+			resources: []restc.Resource{
+				{Name: "Get", Method: "GET", Path: "/{file-name}", Position: pos(10), Params: []restc.Parameter{responder}},
+			},
+		},
+		{
+			name: "multiple bodies",
+			resources: []restc.Resource{
+				{Name: "Create", Method: "POST", Path: "/", Position: pos(10), Params: []restc.Parameter{
+					responder,
+					{Source: restc.ParameterSourceBody, Name: "a", Type: "string"},
+					{Source: restc.ParameterSourceBody, Name: "b", Type: "string"},
+				}},
+			},
+			wantChecks: []string{"task/task.go:10:2: TaskController.Create has 2 body params, only one request body could be decoded"},
+		},
+		{
+			name: "no responder",
+			resources: []restc.Resource{
+				{Name: "Delete", Method: "DELETE", Path: "/", Position: pos(10)},
+			},
+			wantChecks: []string{"task/task.go:10:2: TaskController.Delete has no responder param, handler could not write a response"},
+		},
+		{
+			name: "duplicate route",
+			resources: []restc.Resource{
+				{Name: "List", Method: "GET", Path: "/", Position: pos(10), Params: []restc.Parameter{responder}},
+				{Name: "Search", Method: "get", Path: "/", Position: pos(20), Params: []restc.Parameter{responder}},
+			},
+			wantChecks: []string{"task/task.go:20:2: route GET /tasks/ of TaskController.Search is already handled by TaskController.List"},
+		},
+		{
+			name: "responder methods without status and with the same status",
+			responses: []restc.Response{
+				{Name: "Ok", Annotations: map[string][]string{"@Status": {"200"}}, Position: pos(30)},
+				{Name: "Done", Position: pos(31)},
+			},
+			wantChecks: []string{
+				"task/task.go:31:2: responder TaskResponder method Done has no @Status annotation, 200 is assumed",
+				"task/task.go:31:2: responder TaskResponder methods Ok and Done have the same status 200",
+			},
+		},
+		{
+			name: "unknown type reference",
+			types: map[string]restc.TypeSchema{
+				"example.com/app/task Task": {Name: "Task", Position: pos(40), Schema: &restc.Schema{
+					Type:  "array",
+					Items: &restc.Schema{Ref: "example.com/app/task Label"},
+				}},
+			},
+			wantChecks: []string{"task/task.go:40:2: type example.com/app/task Task references unknown type example.com/app/task Label"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions := restc.NewDefinitions()
+			definitions.Responders["example.com/app/task TaskResponder"] = restc.Responder{
+				Package:   "example.com/app/task",
+				Name:      "TaskResponder",
+				Responses: tt.responses,
+			}
+			controller := restc.Controller{Package: "example.com/app/task", Name: "TaskController", Base: "/tasks", Resources: make(map[string]restc.Resource)}
+			for _, resource := range tt.resources {
+				controller.Resources[resource.Name] = resource
+			}
+			definitions.Controllers[controller.Name] = controller
+			for identifier, ts := range tt.types {
+				definitions.Types[identifier] = ts
+			}
+
+			response, err := generate(&plugin.Request{PluginRequest: restc.PluginRequest{
+				Definitions: definitions,
+				Options:     map[string]string{"file": "report.txt"},
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checks := make([]string, 0)
+			for _, d := range response.Diagnostics {
+				if d.Severity != restc.SeverityWarning {
+					t.Errorf("unexpected severity of %+v", d)
+				}
+				checks = append(checks, fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message))
+			}
+			if tt.wantChecks == nil {
+				tt.wantChecks = []string{}
+			}
+			if !slices.Equal(checks, tt.wantChecks) {
+				t.Errorf("got  %q\nwant %q", checks, tt.wantChecks)
+			}
+
+			if len(response.Files) != 1 || response.Files[0].Path != "report.txt" {
+				t.Errorf("unexpected files %+v", response.Files)
+			}
+		})
+	}
+}
@@ -1,226 +1,364 @@
 package main
 
 import (
-	"encoding/json"
-	"io"
-	"os"
+	"bytes"
+	"embed"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
 )
 
+// defaultTemplates are overridden by templates with the same names from ${RESTC_GIN_TEMPLATES} directory
+//
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// generator holds state of a single plugin request
+type generator struct {
+	definitions restc.Definitions
+	tmpl        *template.Template
+
+	// responderNames maps responder identifiers to names of generated responder implementations
+	responderNames map[string]string
+
+	// response collects generated files and diagnostics
+	response *plugin.Response
+}
+
+// File is the data of the "file" template
+type File struct {
+	Package     string
+	Helpers     bool
+	StdImports  []string
+	Imports     []string
+	Controllers []Controller
+	Responders  []Responder
+}
+
+type Controller struct {
+	Name      string
+	Type      string
+	Resources []Resource
+}
+
+type Resource struct {
+	Name    string
+	Method  string
+	Path    string
+	Summary string
+	Params  []Param
+}
+
+// Param describes handler parameter, Parse and Convert are fmt formats of string parsing expression
+// returning (value, error) and conversion of the parsed value into Elem type, Parse is empty when parsing could not fail
+type Param struct {
+	Name      string
+	Source    string
+	Key       string
+	Type      string
+	Elem      string
+	Pointer   bool
+	Slice     bool
+	Getter    string
+	Parse     string
+	Convert   string
+	Responder string
+}
+
+type Responder struct {
+	Name    string
+	Methods []ResponderMethod
+}
+
+type ResponderMethod struct {
+	Name   string
+	Params []ResponseParam
+	Status string
+	Body   string
+}
+
+type ResponseParam struct {
+	Name string
+	Type string
+}
+
+// Conversion is the data of the "convert" template, it declares Var parsed from Input expression
+type Conversion struct {
+	Param Param
+	Var   string
+	Input string
+}
+
 func main() {
-	payload, err := io.ReadAll(os.Stdin)
+	plugin.Run(generate)
+}
+
+func generate(request *plugin.Request) (*plugin.Response, error) {
+	tmpl, err := LoadTemplates(request.OptionEnv("templates", "RESTC_GIN_TEMPLATES", ""))
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	var definitions restc.Definitions
-	if err := json.Unmarshal(payload, &definitions); err != nil {
-		panic(err)
+	g := newGenerator(request.Definitions, tmpl)
+
+	packageName := request.Option("package", "server")
+
+	layout, err := request.EnumOption("layout", "file", "controller", "package")
+	if err != nil {
+		return nil, err
 	}
 
-	sb := strings.Builder{}
+	controllers := plugin.SortedKeys(g.definitions.Controllers)
+	responders := plugin.SortedKeys(g.definitions.Responders)
 
-	sb.WriteString("// Code generated with RESTc compiler's gin plugin DO NOT EDIT.\n\n")
-	sb.WriteString("package server\n\n")
+	switch layout {
+	case "file":
+		file := g.NewFile(packageName, "", controllers, responders, true)
+		return g.response, g.WriteFile(request.Option("file", "gin_gen.go"), file)
 
-	sb.WriteString("import (\n")
-	sb.WriteString("\t\"github.com/gin-gonic/gin\"\n\n")
-	for _, im := range definitions.Imports {
-		sb.WriteString("\t")
-		sb.WriteString(im)
-		sb.WriteString("\n")
+	case "controller":
+		return g.response, g.WritePackage(".", packageName, "", controllers, responders)
 	}
-	sb.WriteString(")")
 
-	sb.WriteString("\n\n")
+	// files are placed into controller packages, responders are generated for each package using them
+	packageControllers := make(map[string][]string)
+	for _, controller := range request.Controllers() {
+		packageControllers[controller.Package] = append(packageControllers[controller.Package], controller.Name)
+	}
 
-	for name, controller := range definitions.Controllers {
-		sb.WriteString("func GinRegister")
-		sb.WriteString(name)
-		sb.WriteString("(r *gin.Engine, c *")
-		sb.WriteString(controller.Alias)
-		sb.WriteString(") {\n\n")
-		for name, resource := range controller.Resources {
-			sb.WriteString("\tr.Handle(\"")
-			sb.WriteString(resource.Method)
-			sb.WriteString("\", \"")
-			sb.WriteString(NormalizePath(controller.Base + resource.Path))
-			sb.WriteString("\", func(ctx *gin.Context) {\n")
-			for _, param := range resource.Params {
-				switch param.Source {
-				case restc.ParameterSourceHeader:
-					metadata := strings.Split(param.Metadata, " ")
-
-					switch param.Type {
-					case "string":
-						sb.WriteString("\t\t")
-						sb.WriteString(param.Name)
-						sb.WriteString(" := ctx.GetHeader(\"")
-						sb.WriteString(metadata[0])
-						sb.WriteString("\")\n")
-					default:
-						panic("header param type is not supported")
-					}
-
-				case restc.ParameterSourcePath:
-					switch param.Type {
-					case "string":
-						sb.WriteString("\t\t")
-						sb.WriteString(param.Name)
-						sb.WriteString(" := ctx.Param(\"")
-						sb.WriteString(param.Name)
-						sb.WriteString("\")\n")
-					default:
-						panic("path param type is not supported")
-					}
-
-				case restc.ParameterSourceQuery:
-					switch param.Type {
-					case "string":
-						sb.WriteString("\t\t")
-						sb.WriteString(param.Name)
-						sb.WriteString(" := ctx.Query(\"")
-						sb.WriteString(param.Name)
-						sb.WriteString("\")\n")
-					default:
-						panic("query param type is not supported")
-
-					}
-
-					// TODO: type is slice => GetQuery
-
-				case restc.ParameterSourceBody:
-					sb.WriteString("\t\tvar ")
-					sb.WriteString(param.Name)
-					sb.WriteString(" ")
-					sb.WriteString(NormalizeTypeIdentifier(param.Type))
-					sb.WriteString("\n")
-					sb.WriteString("\t\tif err := ctx.BindJSON(&")
-					sb.WriteString(param.Name)
-					sb.WriteString("); err != nil {\n")
-					sb.WriteString("\t\t\treturn\n")
-					sb.WriteString("\t\t}\n")
-				}
-			}
+	for _, packagePath := range plugin.SortedKeys(packageControllers) {
+		controllers := packageControllers[packagePath]
+
+		dir := request.PackageDir(packagePath)
+
+		name, err := PackageName(filepath.Join(dir, path.Base(g.definitions.Controllers[controllers[0]].File)))
+		if err != nil {
+			return nil, err
+		}
+
+		rel, err := request.OutputPath(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := g.WritePackage(rel, name, packagePath, controllers, g.UsedResponders(controllers)); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.response, nil
+}
+
+func newGenerator(definitions restc.Definitions, tmpl *template.Template) *generator {
+	return &generator{
+		definitions:    definitions,
+		tmpl:           tmpl,
+		responderNames: plugin.ShortNames(definitions.Responders, "_"),
+		response:       &plugin.Response{},
+	}
+}
+
+// WritePackage writes file per controller and shared file with responders and helpers into dir
+func (g *generator) WritePackage(dir, packageName, packagePath string, controllers, responders []string) error {
+	for _, name := range controllers {
+		file := g.NewFile(packageName, packagePath, []string{name}, nil, false)
+		if err := g.WriteFile(path.Join(dir, SnakeCase(name)+"_gen.go"), file); err != nil {
+			return err
+		}
+	}
 
-			sb.WriteString("\n")
+	file := g.NewFile(packageName, packagePath, nil, responders, true)
+	return g.WriteFile(path.Join(dir, "responders_gen.go"), file)
+}
+
+// WriteFile adds formatted file to the plugin response, filename is relative to the output directory
+func (g *generator) WriteFile(filename string, file File) error {
+	// code of unsupported params is malformed, it is not returned anyway
+	if g.response.HasErrors() {
+		return nil
+	}
+
+	buf := bytes.Buffer{}
+	if err := g.tmpl.ExecuteTemplate(&buf, "file", file); err != nil {
+		return err
+	}
+
+	return g.response.AddGoFile(filename, buf.String())
+}
+
+// UsedResponders returns sorted identifiers of responders accepted by resources of the controllers
+func (g *generator) UsedResponders(controllers []string) []string {
+	used := make(map[string]bool)
+	for _, name := range controllers {
+		for _, resource := range g.definitions.Controllers[name].Resources {
 			for _, param := range resource.Params {
 				if param.Source == restc.ParameterSourceResponder {
-					sb.WriteString("\t\t")
-					sb.WriteString(param.Name)
-					sb.WriteString(" := ")
-					sb.WriteString("&gin")
-					sb.WriteString(definitions.Responders[param.Type].Name)
-					sb.WriteString("{ctx: ctx}\n")
+					used[param.Type] = true
 				}
 			}
-			sb.WriteString("\n")
-
-			sb.WriteString("\t\tif err := c.")
-			sb.WriteString(name)
-			sb.WriteString("(")
-			for idx, param := range resource.Params {
-				sb.WriteString(param.Name)
-				if idx < len(resource.Params)-1 {
-					sb.WriteString(", ")
+		}
+	}
+	return plugin.SortedKeys(used)
+}
+
+// PackageName reads package clause of the go file
+func PackageName(filename string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	return f.Name.Name, nil
+}
+
+var snakeCaseRegex = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+func SnakeCase(name string) string {
+	return strings.ToLower(snakeCaseRegex.ReplaceAllString(name, "${1}_${2}"))
+}
+
+// LoadTemplates parses embedded templates and *.tmpl files of the override directory if it is set
+func LoadTemplates(overrideDir string) (*template.Template, error) {
+	tmpl, err := template.New("gin").Funcs(template.FuncMap{
+		"conversion": func(param Param, v, input string) Conversion {
+			return Conversion{Param: param, Var: v, Input: input}
+		},
+	}).ParseFS(defaultTemplates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	if overrideDir == "" {
+		return tmpl, nil
+	}
+
+	overrides, err := filepath.Glob(filepath.Join(overrideDir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides) == 0 {
+		return tmpl, nil
+	}
+
+	return tmpl.ParseFiles(overrides...)
+}
+
+// NewFile prepares template data of the file with controllers and responders,
+// types of packagePath are not qualified, imports are known only after all types are formatted
+func (g *generator) NewFile(packageName, packagePath string, controllers, responders []string, helpers bool) File {
+	// imports collects packages referenced by the file, standard packages used by generated code are imported with AddStd
+	imports := plugin.NewImports(packagePath)
+
+	file := File{Package: packageName, Helpers: helpers}
+
+	for _, name := range controllers {
+		controller := g.definitions.Controllers[name]
+
+		c := Controller{
+			Name: name,
+			Type: imports.TypeName(controller.Package + " " + controller.Name),
+		}
+		for _, resource := range plugin.Resources(controller) {
+			r := Resource{
+				Name:   resource.Name,
+				Method: resource.Method,
+				Path: plugin.ReplacePathParams(plugin.FullPath(controller, resource), func(name string) string {
+					return ":" + name
+				}),
+				Summary: resource.Summary,
+			}
+			for _, param := range resource.Params {
+				p, err := g.NewParam(imports, param)
+				if err != nil {
+					g.response.ErrorfAt(param.Position, "resource %s param %s: %s", resource.Name, param.Name, err)
 				}
+				r.Params = append(r.Params, p)
 			}
-			sb.WriteString("); err != nil {\n")
-			sb.WriteString("\t\t\tctx.Error(err)\n\t\t\tctx.Abort()\n\t\t\treturn\n")
-			sb.WriteString("\t\t}\n")
-			sb.WriteString("\t})")
-			sb.WriteString("\n\n")
+			c.Resources = append(c.Resources, r)
 		}
-		sb.WriteString("}\n\n")
+		file.Controllers = append(file.Controllers, c)
 	}
 
-	for _, responder := range definitions.Responders {
-		sb.WriteString("type gin")
-		sb.WriteString(responder.Name)
-		sb.WriteString(" struct {\n")
-		sb.WriteString("\tctx *gin.Context\n")
-		sb.WriteString("}\n\n")
+	for _, identifier := range responders {
+		responder := g.definitions.Responders[identifier]
 
+		r := Responder{Name: g.responderNames[identifier]}
 		for _, response := range responder.Responses {
-			sb.WriteString("func (r *gin")
-			sb.WriteString(responder.Name)
-			sb.WriteString(") ")
-			sb.WriteString(response.Name)
-			sb.WriteString("(")
-			for idx, param := range response.Params {
-				sb.WriteString(param.Name)
-				sb.WriteString(" ")
-				sb.WriteString(NormalizeTypeIdentifier(param.Type))
-				if idx < len(response.Params)-1 {
-					sb.WriteString(", ")
-				}
+			m := ResponderMethod{
+				Name:   response.Name,
+				Status: plugin.ResponseStatus(response),
 			}
-			sb.WriteString(") {\n")
-
-			status := "200"
-			if statusAnnotations, ok := response.Annotations["@Status"]; ok {
-				status = statusAnnotations[0]
+			for _, param := range response.Params {
+				m.Params = append(m.Params, ResponseParam{Name: param.Name, Type: imports.TypeName(param.Type)})
 			}
-
 			if len(response.Params) > 0 {
-				sb.WriteString("\tr.ctx.JSON(")
-				sb.WriteString(status)
-				sb.WriteString(", ")
-				sb.WriteString(response.Params[0].Name)
-				sb.WriteString(")")
-			} else {
-				sb.WriteString("\tr.ctx.Status(")
-				sb.WriteString(status)
-				sb.WriteString(")")
+				m.Body = response.Params[0].Name
 			}
-
-			sb.WriteString("\n}\n\n")
+			r.Methods = append(r.Methods, m)
 		}
+		file.Responders = append(file.Responders, r)
 	}
 
-	os.WriteFile(os.ExpandEnv("${RESTC_OUTPUT}"), []byte(sb.String()), 0666)
-}
+	if helpers {
+		imports.AddStd("net/http")
+	}
+	file.StdImports = imports.StdPaths()
+	file.Imports = imports.Specs()
 
-func NormalizePath(resourcePath string) string {
-	r := regexp.MustCompile(`\{.+?\}`)
-	nameRegex := regexp.MustCompile(`\{([a-zA-Z0-9]+)\}`)
-	return r.ReplaceAllStringFunc(resourcePath, func(s string) string {
-		matches := nameRegex.FindStringSubmatch(s)
-		return ":" + matches[1]
-	})
+	return file
 }
 
-var normalizeTypeIdentifierRegex = regexp.MustCompile(`[\/\.\-]+`)
+// NewParam prepares template data of the resource param, types are qualified with imports of the file
+func (g *generator) NewParam(imports *plugin.Imports, param restc.Parameter) (Param, error) {
+	p := Param{
+		Name:   param.Name,
+		Source: strings.ToLower(string(param.Source)),
+		Key:    param.Name,
+	}
 
-func NormalizeTypeIdentifier(name string) string {
-	parts := strings.Split(name, " ")
-	if len(parts) == 2 {
-		return strings.ToLower(normalizeTypeIdentifierRegex.ReplaceAllString(parts[0], "_")) + "." + parts[1]
-	} else {
-		return name
+	switch param.Source {
+	case restc.ParameterSourceContext:
+		return p, nil
+	case restc.ParameterSourceResponder:
+		p.Responder = g.responderNames[param.Type]
+		return p, nil
+	case restc.ParameterSourceBody:
+		p.Type = imports.TypeName(param.Type)
+		return p, nil
+	case restc.ParameterSourceHeader:
+		p.Key = strings.Split(param.Metadata, " ")[0]
+		p.Getter = "ctx.GetHeader(\"" + p.Key + "\")"
+	case restc.ParameterSourcePath:
+		p.Getter = "ctx.Param(\"" + p.Key + "\")"
+	case restc.ParameterSourceQuery:
+		p.Getter = "ctx.Query(\"" + p.Key + "\")"
 	}
-}
 
-func ConvertString(name, getter, asType string) string {
-	switch asType {
-	case "int":
-	case "int8":
-	case "int16":
-	case "int32":
-	case "int64":
-	case "uint":
-	case "uint8":
-	case "uint16":
-	case "uint32":
-	case "uint64":
-	case "uintptr":
-	case "bool":
-	case "rune":
-	case "byte":
+	elem := param.Type
+	switch {
+	case restc.IsPointerTypeIdentifier(param.Type) && param.Source != restc.ParameterSourcePath:
+		p.Pointer = true
+		elem = restc.ElemTypeIdentifier(param.Type)
+	case restc.IsSliceTypeIdentifier(param.Type) && param.Source == restc.ParameterSourceQuery:
+		p.Slice = true
+		p.Getter = "ctx.QueryArray(\"" + p.Key + "\")"
+		elem = restc.ElemTypeIdentifier(param.Type)
+	case restc.IsCompositeTypeIdentifier(param.Type):
+		return p, fmt.Errorf("%s param type %s is not supported", p.Source, param.Type)
 	}
 
-	return name + " := " + getter
+	p.Type = imports.TypeName(param.Type)
+	p.Elem = imports.TypeName(elem)
+
+	var err error
+	p.Parse, p.Convert, err = plugin.StringParser(g.definitions.Types, imports, elem)
+
+	return p, err
 }
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+func testRequest(t *testing.T, layout string) *plugin.Request {
+	t.Helper()
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "task"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "task", "task.go"), []byte("package task\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	definitions := restc.NewDefinitions()
+	definitions.Types["example.com/app/task Status"] = restc.TypeSchema{Name: "Status", Underlying: "string", Schema: &restc.Schema{Type: "string"}}
+	definitions.Types["example.com/app/task Task"] = restc.TypeSchema{Name: "Task", Schema: &restc.Schema{Type: "object"}}
+	definitions.Responders["example.com/app/task GetResponder"] = restc.Responder{
+		Package: "example.com/app/task",
+		Name:    "GetResponder",
+		Responses: []restc.Response{
+			{Name: "OK", Params: []restc.Parameter{{Name: "task", Type: "example.com/app/task Task"}}},
+			{Name: "Later", Params: []restc.Parameter{{Name: "at", Type: "time Time"}}},
+		},
+	}
+	definitions.Controllers["TaskController"] = restc.Controller{
+		Package: "example.com/app/task",
+		File:    "example.com/app/task/task.go",
+		Name:    "TaskController",
+		Base:    "/api",
+		Resources: map[string]restc.Resource{
+			"Get": {
+				Name:   "Get",
+				Method: "GET",
+				Path:   "/tasks/{id}",
+				Params: []restc.Parameter{
+					{Source: restc.ParameterSourceContext, Name: "ctx", Type: "context Context"},
+					{Source: restc.ParameterSourceResponder, Name: "r", Type: "example.com/app/task GetResponder"},
+					{Source: restc.ParameterSourcePath, Name: "id", Type: "int64"},
+					{Source: restc.ParameterSourceQuery, Name: "since", Type: "*time Time"},
+					{Source: restc.ParameterSourceQuery, Name: "timeout", Type: "time Duration"},
+					{Source: restc.ParameterSourceQuery, Name: "statuses", Type: "[]example.com/app/task Status"},
+					{Source: restc.ParameterSourceHeader, Name: "verbose", Type: "*bool", Metadata: "X-Verbose"},
+				},
+			},
+		},
+	}
+
+	return &plugin.Request{PluginRequest: restc.PluginRequest{
+		Definitions: definitions,
+		Options:     map[string]string{"layout": layout},
+		ModulePath:  "example.com/app",
+		ModuleRoot:  root,
+		Output:      filepath.Join(root, "server"),
+	}}
+}
+
+func TestGenerateImports(t *testing.T) {
+	for _, layout := range []string{"file", "controller", "package"} {
+		t.Run(layout, func(t *testing.T) {
+			response, err := generate(testRequest(t, layout))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %v", response.Diagnostics)
+			}
+
+			for _, f := range response.Files {
+				file, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, parser.ImportsOnly)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// time is used by conversions and types, it must be imported once
+				names := make(map[string]string)
+				for _, spec := range file.Imports {
+					path, _ := strconv.Unquote(spec.Path.Value)
+					name := restc.ImportPathName(path)
+					if spec.Name != nil {
+						name = spec.Name.Name
+					}
+
+					if other, ok := names[name]; ok {
+						t.Errorf("%s: %s is imported as %s and %s", f.Path, name, other, path)
+					}
+					names[name] = path
+				}
+			}
+		})
+	}
+}
+
+func TestNewParam(t *testing.T) {
+	g := newGenerator(testRequest(t, "file").Definitions, nil)
+
+	tests := []struct {
+		param restc.Parameter
+		want  Param
+		err   bool
+	}{
+		{
+			param: restc.Parameter{Source: restc.ParameterSourcePath, Name: "id", Type: "int64"},
+			want:  Param{Name: "id", Source: "path", Key: "id", Type: "int64", Elem: "int64", Getter: `ctx.Param("id")`, Parse: "strconv.ParseInt(%s, 10, 64)", Convert: "%s"},
+		},
+		{
+			param: restc.Parameter{Source: restc.ParameterSourceQuery, Name: "since", Type: "*time Time"},
+			want:  Param{Name: "since", Source: "query", Key: "since", Type: "*time.Time", Elem: "time.Time", Pointer: true, Getter: `ctx.Query("since")`, Parse: "time.Parse(time.RFC3339Nano, %s)", Convert: "%s"},
+		},
+		{
+			param: restc.Parameter{Source: restc.ParameterSourceQuery, Name: "statuses", Type: "[]example.com/app/task Status"},
+			want:  Param{Name: "statuses", Source: "query", Key: "statuses", Type: "[]example_com_app_task.Status", Elem: "example_com_app_task.Status", Slice: true, Getter: `ctx.QueryArray("statuses")`, Convert: "example_com_app_task.Status(%s)"},
+		},
+		{
+			param: restc.Parameter{Source: restc.ParameterSourceHeader, Name: "verbose", Type: "*bool", Metadata: "X-Verbose optional"},
+			want:  Param{Name: "verbose", Source: "header", Key: "X-Verbose", Type: "*bool", Elem: "bool", Pointer: true, Getter: `ctx.GetHeader("X-Verbose")`, Parse: "strconv.ParseBool(%s)", Convert: "%s"},
+		},
+		{
+			param: restc.Parameter{Source: restc.ParameterSourceResponder, Name: "r", Type: "example.com/app/task GetResponder"},
+			want:  Param{Name: "r", Source: "responder", Key: "r", Responder: "GetResponder"},
+		},
+		{param: restc.Parameter{Source: restc.ParameterSourcePath, Name: "id", Type: "*int64"}, err: true},
+		{param: restc.Parameter{Source: restc.ParameterSourceHeader, Name: "tags", Type: "[]string"}, err: true},
+		{param: restc.Parameter{Source: restc.ParameterSourceQuery, Name: "task", Type: "example.com/app/task Task"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.param.Name+" "+tt.param.Type, func(t *testing.T) {
+			p, err := g.NewParam(plugin.NewImports(""), tt.param)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("error expected, got %+v", p)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p != tt.want {
+				t.Errorf("got  %+v\nwant %+v", p, tt.want)
+			}
+		})
+	}
+}
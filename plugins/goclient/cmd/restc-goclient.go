@@ -0,0 +1,358 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+// generator holds state of a single plugin request
+type generator struct {
+	definitions restc.Definitions
+
+	// responderNames maps responder identifiers to prefixes of generated result types
+	responderNames map[string]string
+
+	// imports collects packages referenced by generated code, only they are imported
+	imports *plugin.Imports
+}
+
+func main() {
+	plugin.Run(generate)
+}
+
+func generate(request *plugin.Request) (*plugin.Response, error) {
+	g := &generator{
+		definitions:    request.Definitions,
+		responderNames: plugin.ShortNames(request.Definitions.Responders, "_"),
+		imports:        plugin.NewImports(""),
+	}
+
+	// packages used by helpers, types of them are qualified with the same names
+	for _, packagePath := range []string{"bytes", "context", "encoding/json", "fmt", "io", "net/http", "net/url", "time"} {
+		g.imports.AddStd(packagePath)
+	}
+
+	sb := strings.Builder{}
+
+	for _, controller := range request.Controllers() {
+		clientName := strings.TrimSuffix(controller.Name, "Controller") + "Client"
+
+		sb.WriteString("type ")
+		sb.WriteString(clientName)
+		sb.WriteString(" struct {\n")
+		sb.WriteString("\tbaseURL    string\n")
+		sb.WriteString("\thttpClient *http.Client\n")
+		sb.WriteString("}\n\n")
+
+		g.imports.AddStd("strings")
+		sb.WriteString("func New")
+		sb.WriteString(clientName)
+		sb.WriteString("(baseURL string, httpClient *http.Client) *")
+		sb.WriteString(clientName)
+		sb.WriteString(" {\n")
+		sb.WriteString("\tif httpClient == nil {\n\t\thttpClient = http.DefaultClient\n\t}\n")
+		sb.WriteString("\treturn &")
+		sb.WriteString(clientName)
+		sb.WriteString("{baseURL: strings.TrimSuffix(baseURL, \"/\"), httpClient: httpClient}\n")
+		sb.WriteString("}\n\n")
+
+		for _, resource := range plugin.Resources(controller) {
+			g.WriteResourceMethod(&sb, clientName, controller, resource)
+		}
+	}
+
+	for _, identifier := range plugin.SortedKeys(g.definitions.Responders) {
+		g.WriteResult(&sb, g.ResultResponder(identifier))
+	}
+
+	header := strings.Builder{}
+	header.WriteString("// Code generated with RESTc compiler's goclient plugin DO NOT EDIT.\n\n")
+	header.WriteString("package ")
+	header.WriteString(request.Option("package", "client"))
+	header.WriteString("\n\n")
+
+	header.WriteString("import (\n")
+	for _, packagePath := range g.imports.StdPaths() {
+		header.WriteString("\t\"")
+		header.WriteString(packagePath)
+		header.WriteString("\"\n")
+	}
+
+	specs := g.imports.Specs()
+	if len(specs) > 0 {
+		header.WriteString("\n")
+	}
+	for _, im := range specs {
+		header.WriteString("\t")
+		header.WriteString(im)
+		header.WriteString("\n")
+	}
+	header.WriteString(")\n\n")
+
+	header.WriteString(helpers)
+
+	response := &plugin.Response{}
+	return response, response.AddGoFile(request.Option("file", "client_gen.go"), header.String()+sb.String())
+}
+
+func (g *generator) WriteResourceMethod(sb *strings.Builder, clientName string, controller restc.Controller, resource restc.Resource) {
+	var responder *restc.Responder
+	var body *restc.Parameter
+
+	sb.WriteString("func (c *")
+	sb.WriteString(clientName)
+	sb.WriteString(") ")
+	sb.WriteString(resource.Name)
+	sb.WriteString("(ctx context.Context")
+	for _, param := range resource.Params {
+		switch param.Source {
+		case restc.ParameterSourceContext:
+			continue
+		case restc.ParameterSourceResponder:
+			r := g.ResultResponder(param.Type)
+			responder = &r
+			continue
+		case restc.ParameterSourceBody:
+			p := param
+			body = &p
+		}
+		sb.WriteString(", ")
+		sb.WriteString(param.Name)
+		sb.WriteString(" ")
+		sb.WriteString(g.imports.TypeName(param.Type))
+	}
+
+	if responder != nil {
+		sb.WriteString(") (*")
+		sb.WriteString(responder.Name)
+		sb.WriteString("Result, error) {\n")
+	} else {
+		sb.WriteString(") error {\n")
+	}
+
+	errorReturn := "err"
+	if responder != nil {
+		errorReturn = "nil, err"
+	}
+
+	// path with escaped path params
+	sb.WriteString("\turlPath := ")
+	segments := plugin.SplitPath(plugin.FullPath(controller, resource))
+	if len(segments) == 0 {
+		sb.WriteString(`""`)
+	}
+	for idx, segment := range segments {
+		if idx > 0 {
+			sb.WriteString(" + ")
+		}
+		if segment.Param != "" {
+			sb.WriteString("url.PathEscape(formatParam(" + segment.Param + "))")
+		} else {
+			sb.WriteString(`"` + segment.Literal + `"`)
+		}
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString("\turlQuery := url.Values{}\n")
+	for _, param := range resource.Params {
+		if param.Source != restc.ParameterSourceQuery {
+			continue
+		}
+		WriteParamValue(sb, param, "urlQuery.Add(\""+param.Name+"\", ", ")")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("\thttpReq, err := newRequest(ctx, \"")
+	sb.WriteString(strings.ToUpper(resource.Method))
+	sb.WriteString("\", c.baseURL+urlPath, urlQuery, ")
+	if body != nil {
+		sb.WriteString(body.Name)
+	} else {
+		sb.WriteString("nil")
+	}
+	sb.WriteString(")\n")
+	sb.WriteString("\tif err != nil {\n\t\treturn " + errorReturn + "\n\t}\n\n")
+
+	for _, param := range resource.Params {
+		if param.Source != restc.ParameterSourceHeader {
+			continue
+		}
+		headerName := param.Name
+		if metadata := strings.Fields(param.Metadata); len(metadata) > 0 {
+			headerName = metadata[0]
+		}
+		WriteParamValue(sb, param, "httpReq.Header.Add(\""+headerName+"\", ", ")")
+	}
+
+	sb.WriteString("\n\thttpResp, err := c.httpClient.Do(httpReq)\n")
+	sb.WriteString("\tif err != nil {\n\t\treturn " + errorReturn + "\n\t}\n")
+	sb.WriteString("\tdefer httpResp.Body.Close()\n\n")
+
+	if responder == nil {
+		sb.WriteString("\tif httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {\n")
+		sb.WriteString("\t\treturn newUnexpectedStatusError(httpResp)\n")
+		sb.WriteString("\t}\n\n")
+		sb.WriteString("\treturn nil\n")
+		sb.WriteString("}\n\n")
+		return
+	}
+
+	sb.WriteString("\tresult := &")
+	sb.WriteString(responder.Name)
+	sb.WriteString("Result{StatusCode: httpResp.StatusCode}\n\n")
+
+	sb.WriteString("\tswitch httpResp.StatusCode {\n")
+	statuses := make(map[string]bool)
+	for _, response := range responder.Responses {
+		status := plugin.ResponseStatus(response)
+		// server responses with the same status could not be distinguished, first method wins
+		if statuses[status] {
+			continue
+		}
+		statuses[status] = true
+
+		sb.WriteString("\tcase ")
+		sb.WriteString(status)
+		sb.WriteString(":\n")
+		sb.WriteString("\t\tresult.Response = ")
+		sb.WriteString(responder.Name)
+		sb.WriteString(response.Name)
+		sb.WriteString("\n")
+		if len(response.Params) > 0 {
+			sb.WriteString("\t\tif err := json.NewDecoder(httpResp.Body).Decode(&result.")
+			sb.WriteString(response.Name)
+			sb.WriteString("); err != nil {\n")
+			sb.WriteString("\t\t\treturn nil, err\n")
+			sb.WriteString("\t\t}\n")
+		}
+	}
+	sb.WriteString("\tdefault:\n")
+	sb.WriteString("\t\treturn nil, newUnexpectedStatusError(httpResp)\n")
+	sb.WriteString("\t}\n\n")
+	sb.WriteString("\treturn result, nil\n")
+	sb.WriteString("}\n\n")
+}
+
+// WriteParamValue writes statement passing formatted param value, nil pointers are skipped and slices are passed per element
+func WriteParamValue(sb *strings.Builder, param restc.Parameter, prefix, suffix string) {
+	switch {
+	case restc.IsPointerTypeIdentifier(param.Type):
+		sb.WriteString("\tif " + param.Name + " != nil {\n")
+		sb.WriteString("\t\t" + prefix + "formatParam(*" + param.Name + ")" + suffix + "\n")
+		sb.WriteString("\t}\n")
+	case restc.IsSliceTypeIdentifier(param.Type) || restc.IsArrayTypeIdentifier(param.Type):
+		sb.WriteString("\tfor _, v := range " + param.Name + " {\n")
+		sb.WriteString("\t\t" + prefix + "formatParam(v)" + suffix + "\n")
+		sb.WriteString("\t}\n")
+	default:
+		sb.WriteString("\t" + prefix + "formatParam(" + param.Name + ")" + suffix + "\n")
+	}
+}
+
+// ResultResponder returns responder named with its unique name, result types are named after it
+func (g *generator) ResultResponder(identifier string) restc.Responder {
+	responder := g.definitions.Responders[identifier]
+	responder.Name = g.responderNames[identifier]
+	return responder
+}
+
+// WriteResult writes result type of the responder: invoked responder method name, status code and decoded payloads
+func (g *generator) WriteResult(sb *strings.Builder, responder restc.Responder) {
+	sb.WriteString("type ")
+	sb.WriteString(responder.Name)
+	sb.WriteString("Response string\n\n")
+
+	sb.WriteString("const (\n")
+	for _, response := range responder.Responses {
+		sb.WriteString("\t")
+		sb.WriteString(responder.Name)
+		sb.WriteString(response.Name)
+		sb.WriteString(" ")
+		sb.WriteString(responder.Name)
+		sb.WriteString("Response = \"")
+		sb.WriteString(response.Name)
+		sb.WriteString("\"\n")
+	}
+	sb.WriteString(")\n\n")
+
+	sb.WriteString("type ")
+	sb.WriteString(responder.Name)
+	sb.WriteString("Result struct {\n")
+	sb.WriteString("\tResponse   ")
+	sb.WriteString(responder.Name)
+	sb.WriteString("Response\n")
+	sb.WriteString("\tStatusCode int\n")
+	for _, response := range responder.Responses {
+		if len(response.Params) == 0 {
+			continue
+		}
+		sb.WriteString("\t")
+		sb.WriteString(response.Name)
+		sb.WriteString(" ")
+		// payload is nil unless the method was invoked
+		paramType := g.imports.TypeName(response.Params[0].Type)
+		if !restc.IsCompositeTypeIdentifier(response.Params[0].Type) || restc.IsArrayTypeIdentifier(response.Params[0].Type) {
+			paramType = "*" + paramType
+		}
+		sb.WriteString(paramType)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n\n")
+}
+
+const helpers = `type UnexpectedStatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %d: %s", e.StatusCode, e.Body)
+}
+
+func newUnexpectedStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	return &UnexpectedStatusError{StatusCode: resp.StatusCode, Body: body}
+}
+
+func newRequest(ctx context.Context, method, rawURL string, query url.Values, body any) (*http.Request, error) {
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	return req, nil
+}
+
+func formatParam(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return value.String()
+	}
+	return fmt.Sprint(v)
+}
+
+`
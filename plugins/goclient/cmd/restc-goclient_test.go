@@ -0,0 +1,92 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+	"github.com/tulinowpavel/restc/plugins/internal/plugintest"
+)
+
+// typeCheck parses and type checks generated file, only standard packages could be imported
+func typeCheck(t *testing.T, content string) *ast.File {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client_gen.go", content, 0)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, content)
+	}
+
+	config := types.Config{Importer: importer.Default()}
+	if _, err := config.Check(file.Name.Name, fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("%s\n%s", err, content)
+	}
+	return file
+}
+
+func TestGenerate(t *testing.T) {
+	withController := restc.NewDefinitions()
+	withController.Controllers["TaskController"] = restc.Controller{
+		Name: "TaskController",
+		Base: "/api",
+		Resources: map[string]restc.Resource{
+			"Get": {
+				Name:   "Get",
+				Method: "GET",
+				Path:   "/tasks/{id}",
+				Params: []restc.Parameter{
+					{Source: restc.ParameterSourceContext, Name: "ctx", Type: "context Context"},
+					{Source: restc.ParameterSourcePath, Name: "id", Type: "int64"},
+					{Source: restc.ParameterSourceQuery, Name: "since", Type: "*time Time"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		definitions restc.Definitions
+		options     map[string]string
+		pkg         string
+	}{
+		{name: "empty definitions", definitions: restc.NewDefinitions(), pkg: "client"},
+		{name: "controller", definitions: withController, pkg: "client"},
+		{name: "package option", definitions: withController, options: map[string]string{"package": "tasks"}, pkg: "tasks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := generate(&plugin.Request{PluginRequest: restc.PluginRequest{Definitions: tt.definitions, Options: tt.options}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.HasErrors() || len(response.Files) != 1 {
+				t.Fatalf("unexpected response %+v", response)
+			}
+
+			if file := typeCheck(t, response.Files[0].Content); file.Name.Name != tt.pkg {
+				t.Errorf("package %s, want %s", file.Name.Name, tt.pkg)
+			}
+		})
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	response, err := generate(&plugin.Request{PluginRequest: restc.PluginRequest{
+		Definitions: plugintest.Definitions(t),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics %+v", response.Diagnostics)
+	}
+
+	plugintest.Golden(t, response)
+}
@@ -0,0 +1,64 @@
+// Package plugintest provides the definitions fixture shared by tests of bundled plugins
+// and compares generated files with golden files
+package plugintest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with generated content")
+
+// definitions are dumped with restc dump from the project with nullable, slice, map, array and nested types
+//
+//go:embed testdata/definitions.json
+var definitions []byte
+
+// Definitions returns the shared definitions fixture
+func Definitions(t *testing.T) restc.Definitions {
+	t.Helper()
+
+	var d restc.Definitions
+	if err := json.Unmarshal(definitions, &d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// Golden compares response files with testdata/<path>.golden of the test package, golden files are rewritten with -update
+func Golden(t *testing.T, response *plugin.Response) {
+	t.Helper()
+
+	if len(response.Files) == 0 {
+		t.Fatal("no files generated")
+	}
+
+	for _, f := range response.Files {
+		golden := filepath.Join("testdata", filepath.FromSlash(f.Path)+".golden")
+
+		if *update {
+			if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(golden, []byte(f.Content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Content != string(want) {
+			t.Errorf("%s differs from %s, run go test -update to rewrite it\n%s", f.Path, golden, f.Content)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+// generator holds state of a single plugin request
+type generator struct {
+	definitions restc.Definitions
+
+	// imports collects packages referenced by generated code, standard packages used by it are imported with AddStd
+	imports *plugin.Imports
+
+	// responderNames maps responder identifiers to names of generated responder implementations
+	responderNames map[string]string
+
+	// response collects generated file and diagnostics
+	response *plugin.Response
+}
+
+func main() {
+	plugin.Run(generate)
+}
+
+func generate(request *plugin.Request) (*plugin.Response, error) {
+	g := &generator{
+		definitions:    request.Definitions,
+		imports:        plugin.NewImports(""),
+		responderNames: plugin.ShortNames(request.Definitions.Responders, "_"),
+		response:       &plugin.Response{},
+	}
+
+	g.imports.AddStd("net/http")
+	if UsesJSON(g.definitions) {
+		g.imports.AddStd("encoding/json")
+	}
+
+	sb := strings.Builder{}
+
+	for _, controller := range request.Controllers() {
+		sb.WriteString("func Register")
+		sb.WriteString(controller.Name)
+		sb.WriteString("(mux *http.ServeMux, c *")
+		sb.WriteString(g.imports.TypeName(controller.Package + " " + controller.Name))
+		sb.WriteString(") {\n\n")
+		for _, resource := range plugin.Resources(controller) {
+			g.WriteHandler(&sb, controller, resource)
+		}
+		sb.WriteString("}\n\n")
+	}
+
+	for _, identifier := range plugin.SortedKeys(g.definitions.Responders) {
+		g.WriteResponder(&sb, g.responderNames[identifier], g.definitions.Responders[identifier])
+	}
+
+	// code of unsupported params is malformed, it is not returned anyway
+	if g.response.HasErrors() {
+		return g.response, nil
+	}
+
+	header := strings.Builder{}
+	header.WriteString("// Code generated with RESTc compiler's nethttp plugin DO NOT EDIT.\n\n")
+	header.WriteString("package ")
+	header.WriteString(request.Option("package", "server"))
+	header.WriteString("\n\n")
+
+	header.WriteString("import (\n")
+	for _, packagePath := range g.imports.StdPaths() {
+		header.WriteString("\t\"")
+		header.WriteString(packagePath)
+		header.WriteString("\"\n")
+	}
+	header.WriteString("\n")
+	for _, im := range g.imports.Specs() {
+		header.WriteString("\t")
+		header.WriteString(im)
+		header.WriteString("\n")
+	}
+	header.WriteString(")")
+
+	header.WriteString("\n\n")
+
+	return g.response, g.response.AddGoFile(request.Option("file", "nethttp_gen.go"), header.String()+sb.String())
+}
+
+// WriteHandler writes mux handler of the resource decoding its params
+func (g *generator) WriteHandler(sb *strings.Builder, controller restc.Controller, resource restc.Resource) {
+	sb.WriteString("\tmux.HandleFunc(\"")
+	sb.WriteString(Pattern(controller, resource))
+	sb.WriteString("\", func(w http.ResponseWriter, req *http.Request) {\n")
+	for _, param := range resource.Params {
+		if err := g.WriteParam(sb, param); err != nil {
+			g.response.ErrorfAt(param.Position, "resource %s param %s: %s", resource.Name, param.Name, err)
+		}
+	}
+
+	sb.WriteString("\n")
+	for _, param := range resource.Params {
+		if param.Source == restc.ParameterSourceResponder {
+			sb.WriteString("\t\t")
+			sb.WriteString(param.Name)
+			sb.WriteString(" := ")
+			sb.WriteString("&http")
+			sb.WriteString(g.responderNames[param.Type])
+			sb.WriteString("{w: w}\n")
+		}
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("\t\tif err := c.")
+	sb.WriteString(resource.Name)
+	sb.WriteString("(")
+	for idx, param := range resource.Params {
+		sb.WriteString(param.Name)
+		if idx < len(resource.Params)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString("); err != nil {\n")
+	sb.WriteString("\t\t\thttp.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)\n\t\t\treturn\n")
+	sb.WriteString("\t\t}\n")
+	sb.WriteString("\t})")
+	sb.WriteString("\n\n")
+}
+
+// Pattern returns ServeMux pattern of the resource, patterns use the same {param} syntax as resources.
+// Paths ending with slash match only themselves instead of the whole subtree
+func Pattern(controller restc.Controller, resource restc.Resource) string {
+	resourcePath := plugin.FullPath(controller, resource)
+	if strings.HasSuffix(resourcePath, "/") {
+		resourcePath += "{$}"
+	}
+	return strings.ToUpper(resource.Method) + " " + resourcePath
+}
+
+// WriteParam declares handler variable of the param, path, query and header values are converted
+// like gin plugin does: pointers of query and header params are nil when the value is missing,
+// slices are read from repeated query params
+func (g *generator) WriteParam(sb *strings.Builder, param restc.Parameter) error {
+	var getter, present string
+
+	switch param.Source {
+	case restc.ParameterSourceContext:
+		sb.WriteString("\t\t" + param.Name + " := req.Context()\n")
+		return nil
+	case restc.ParameterSourceResponder:
+		return nil
+	case restc.ParameterSourceBody:
+		sb.WriteString("\t\tvar " + param.Name + " " + g.imports.TypeName(param.Type) + "\n")
+		sb.WriteString("\t\tif err := json.NewDecoder(req.Body).Decode(&" + param.Name + "); err != nil {\n")
+		sb.WriteString("\t\t\thttp.Error(w, err.Error(), http.StatusBadRequest)\n")
+		sb.WriteString("\t\t\treturn\n")
+		sb.WriteString("\t\t}\n")
+		return nil
+	case restc.ParameterSourceHeader:
+		key := strings.Split(param.Metadata, " ")[0]
+		getter = "req.Header.Get(\"" + key + "\")"
+		present = getter + " != \"\""
+	case restc.ParameterSourcePath:
+		getter = "req.PathValue(\"" + param.Name + "\")"
+	case restc.ParameterSourceQuery:
+		getter = "req.URL.Query().Get(\"" + param.Name + "\")"
+		present = "req.URL.Query().Has(\"" + param.Name + "\")"
+	}
+
+	source := strings.ToLower(string(param.Source))
+	onError := "\t\t\thttp.Error(w, \"invalid " + source + " parameter " + param.Name + ": \"+err.Error(), http.StatusBadRequest)\n\t\t\treturn\n"
+
+	switch {
+	case restc.IsPointerTypeIdentifier(param.Type) && present != "":
+		parse, convert, err := plugin.StringParser(g.definitions.Types, g.imports, restc.ElemTypeIdentifier(param.Type))
+		if err != nil {
+			return err
+		}
+
+		sb.WriteString("\t\tvar " + param.Name + " " + g.imports.TypeName(param.Type) + "\n")
+		sb.WriteString("\t\tif " + present + " {\n")
+		WriteConversion(sb, "v", getter, parse, convert, onError)
+		sb.WriteString("\t\t\t" + param.Name + " = &v\n")
+		sb.WriteString("\t\t}\n")
+	case restc.IsSliceTypeIdentifier(param.Type) && param.Source == restc.ParameterSourceQuery:
+		values := "req.URL.Query()[\"" + param.Name + "\"]"
+
+		parse, convert, err := plugin.StringParser(g.definitions.Types, g.imports, restc.ElemTypeIdentifier(param.Type))
+		if err != nil {
+			return err
+		}
+
+		if parse == "" && convert == "%s" {
+			sb.WriteString("\t\t" + param.Name + " := " + values + "\n")
+			return nil
+		}
+
+		sb.WriteString("\t\tvar " + param.Name + " " + g.imports.TypeName(param.Type) + "\n")
+		sb.WriteString("\t\tfor _, s := range " + values + " {\n")
+		WriteConversion(sb, "v", "s", parse, convert, onError)
+		sb.WriteString("\t\t\t" + param.Name + " = append(" + param.Name + ", v)\n")
+		sb.WriteString("\t\t}\n")
+	case restc.IsCompositeTypeIdentifier(param.Type):
+		return fmt.Errorf("%s param type %s is not supported", source, param.Type)
+	default:
+		parse, convert, err := plugin.StringParser(g.definitions.Types, g.imports, param.Type)
+		if err != nil {
+			return err
+		}
+
+		WriteConversion(sb, param.Name, getter, parse, convert, onError)
+	}
+
+	return nil
+}
+
+// WriteConversion declares v parsed from input string expression with parse and convert formats of plugin.StringParser,
+// the file is formatted afterwards, so statements are not indented by nesting
+func WriteConversion(sb *strings.Builder, v, input, parse, convert, onError string) {
+	switch {
+	case parse == "":
+		sb.WriteString("\t\t\t" + v + " := " + fmt.Sprintf(convert, input) + "\n")
+	case convert == "%s":
+		sb.WriteString("\t\t\t" + v + ", err := " + fmt.Sprintf(parse, input) + "\n")
+		sb.WriteString("\t\t\tif err != nil {\n" + onError + "\t\t\t}\n")
+	default:
+		sb.WriteString("\t\t\t" + v + "Value, err := " + fmt.Sprintf(parse, input) + "\n")
+		sb.WriteString("\t\t\tif err != nil {\n" + onError + "\t\t\t}\n")
+		sb.WriteString("\t\t\t" + v + " := " + fmt.Sprintf(convert, v+"Value") + "\n")
+	}
+}
+
+// WriteResponder writes responder implementation over http.ResponseWriter
+func (g *generator) WriteResponder(sb *strings.Builder, name string, responder restc.Responder) {
+	sb.WriteString("type http")
+	sb.WriteString(name)
+	sb.WriteString(" struct {\n")
+	sb.WriteString("\tw http.ResponseWriter\n")
+	sb.WriteString("}\n\n")
+
+	for _, response := range responder.Responses {
+		sb.WriteString("func (r *http")
+		sb.WriteString(name)
+		sb.WriteString(") ")
+		sb.WriteString(response.Name)
+		sb.WriteString("(")
+		for idx, param := range response.Params {
+			sb.WriteString(param.Name)
+			sb.WriteString(" ")
+			sb.WriteString(g.imports.TypeName(param.Type))
+			if idx < len(response.Params)-1 {
+				sb.WriteString(", ")
+			}
+		}
+		sb.WriteString(") {\n")
+
+		status := plugin.ResponseStatus(response)
+
+		if len(response.Params) > 0 {
+			sb.WriteString("\tr.w.Header().Set(\"Content-Type\", \"application/json\")\n")
+			sb.WriteString("\tr.w.WriteHeader(")
+			sb.WriteString(status)
+			sb.WriteString(")\n")
+			sb.WriteString("\tjson.NewEncoder(r.w).Encode(")
+			sb.WriteString(response.Params[0].Name)
+			sb.WriteString(")")
+		} else {
+			sb.WriteString("\tr.w.WriteHeader(")
+			sb.WriteString(status)
+			sb.WriteString(")")
+		}
+
+		sb.WriteString("\n}\n\n")
+	}
+}
+
+// UsesJSON reports whether generated code decodes bodies or encodes responses
+func UsesJSON(definitions restc.Definitions) bool {
+	for _, controller := range definitions.Controllers {
+		for _, resource := range controller.Resources {
+			for _, param := range resource.Params {
+				if param.Source == restc.ParameterSourceBody {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, responder := range definitions.Responders {
+		for _, response := range responder.Responses {
+			if len(response.Params) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
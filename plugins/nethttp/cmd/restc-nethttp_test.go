@@ -0,0 +1,67 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		base   string
+		method string
+		path   string
+		want   string
+	}{
+		{base: "/api", method: "GET", path: "/tasks/{id}", want: "GET /api/tasks/{id}"},
+		{base: "/api/", method: "post", path: "/tasks", want: "POST /api/tasks"},
+		{base: "/api/tasks", method: "POST", path: "/scope/{scope}/", want: "POST /api/tasks/scope/{scope}/{$}"},
+		{base: "/api", method: "GET", path: "/", want: "GET /api/{$}"},
+		{base: "", method: "GET", path: "/", want: "GET /{$}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := Pattern(restc.Controller{Base: tt.base}, restc.Resource{Method: tt.method, Path: tt.path})
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePatterns(t *testing.T) {
+	definitions := restc.NewDefinitions()
+	definitions.Controllers["TaskController"] = restc.Controller{
+		Package: "example.com/app/task",
+		Name:    "TaskController",
+		Base:    "/api/tasks",
+		Resources: map[string]restc.Resource{
+			"List":   {Name: "List", Method: "GET", Path: "/"},
+			"Get":    {Name: "Get", Method: "GET", Path: "/{id}", Params: []restc.Parameter{{Source: restc.ParameterSourcePath, Name: "id", Type: "string"}}},
+			"Create": {Name: "Create", Method: "POST", Path: "/scope/{scope}/", Params: []restc.Parameter{{Source: restc.ParameterSourcePath, Name: "scope", Type: "string"}}},
+		},
+	}
+
+	response, err := generate(&plugin.Request{PluginRequest: restc.PluginRequest{Definitions: definitions}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.HasErrors() || len(response.Files) != 1 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	patterns := make([]string, 0)
+	for _, m := range regexp.MustCompile(`mux\.HandleFunc\("([^"]*)"`).FindAllStringSubmatch(response.Files[0].Content, -1) {
+		patterns = append(patterns, m[1])
+	}
+	slices.Sort(patterns)
+
+	want := []string{"GET /api/tasks/{$}", "GET /api/tasks/{id}", "POST /api/tasks/scope/{scope}/{$}"}
+	if !slices.Equal(patterns, want) {
+		t.Errorf("got patterns %q, want %q", patterns, want)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+	Tags       []Tag                `json:"tags,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+	Trace   *Operation `json:"trace,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+
+	// merged reports whether the content schema is oneOf of merged responder methods
+	merged bool
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string                                  `json:"$ref,omitempty"`
+	Type                 any                                     `json:"type,omitempty"`
+	Format               string                                  `json:"format,omitempty"`
+	Items                *Schema                                 `json:"items,omitempty"`
+	Required             []string                                `json:"required,omitempty"`
+	Properties           *orderedmap.OrderedMap[string, *Schema] `json:"properties,omitempty"`
+	AdditionalProperties *Schema                                 `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema                               `json:"allOf,omitempty"`
+	OneOf                []*Schema                               `json:"oneOf,omitempty"`
+}
+
+func main() {
+	plugin.Run(generate)
+}
+
+func generate(request *plugin.Request) (*plugin.Response, error) {
+	definitions := request.Definitions
+
+	doc := Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:   request.OptionEnv("title", "RESTC_OPENAPI_TITLE", "API"),
+			Version: request.OptionEnv("version", "RESTC_OPENAPI_VERSION", "0.0.0"),
+		},
+		Paths: make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+
+	componentNames := plugin.ShortNames(definitions.Types, ".")
+
+	for identifier, ts := range definitions.Types {
+		schema := ConvertSchema(ts.Schema, componentNames)
+		if schema == nil {
+			schema = &Schema{Type: "object"}
+		}
+		doc.Components.Schemas[componentNames[identifier]] = schema
+	}
+
+	tags := make(map[string]struct{})
+
+	for _, controller := range request.Controllers() {
+		for _, resource := range plugin.Resources(controller) {
+			operation := &Operation{
+				OperationID: controller.Name + "." + resource.Name,
+				Summary:     resource.Summary,
+				Description: resource.Details,
+				Tags:        resource.Tags,
+				Responses:   make(map[string]*Response),
+			}
+
+			for _, tag := range resource.Tags {
+				tags[tag] = struct{}{}
+			}
+
+			for _, param := range resource.Params {
+				switch param.Source {
+				case restc.ParameterSourcePath:
+					operation.Parameters = append(operation.Parameters, Parameter{
+						Name:     param.Name,
+						In:       "path",
+						Required: true,
+						Schema:   TypeSchema(param.Type, componentNames),
+					})
+				case restc.ParameterSourceQuery:
+					operation.Parameters = append(operation.Parameters, Parameter{
+						Name:   param.Name,
+						In:     "query",
+						Schema: TypeSchema(param.Type, componentNames),
+					})
+				case restc.ParameterSourceHeader:
+					name := param.Name
+					if metadata := strings.Fields(param.Metadata); len(metadata) > 0 {
+						name = metadata[0]
+					}
+					operation.Parameters = append(operation.Parameters, Parameter{
+						Name:   name,
+						In:     "header",
+						Schema: TypeSchema(param.Type, componentNames),
+					})
+				case restc.ParameterSourceBody:
+					operation.RequestBody = &RequestBody{
+						Required: !restc.IsPointerTypeIdentifier(param.Type),
+						Content: map[string]MediaType{
+							"application/json": {Schema: TypeSchema(param.Type, componentNames)},
+						},
+					}
+				case restc.ParameterSourceResponder:
+					responder, ok := definitions.Responders[param.Type]
+					if !ok {
+						return nil, fmt.Errorf("responder %s not found", param.Type)
+					}
+					for _, response := range responder.Responses {
+						AddResponse(operation, response, componentNames)
+					}
+				}
+			}
+
+			if len(operation.Responses) == 0 {
+				operation.Responses["200"] = &Response{Description: "OK"}
+			}
+
+			resourcePath := plugin.FullPath(controller, resource)
+			item, ok := doc.Paths[resourcePath]
+			if !ok {
+				item = &PathItem{}
+				doc.Paths[resourcePath] = item
+			}
+
+			if !item.SetOperation(resource.Method, operation) {
+				return nil, fmt.Errorf("unsupported method %s of resource %s", resource.Method, operation.OperationID)
+			}
+		}
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	output := request.Option("file", "openapi.yaml")
+
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(output, ".yaml") || strings.HasSuffix(output, ".yml") {
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			return nil, err
+		}
+		ResetStyle(&node)
+
+		content, err = yaml.Marshal(&node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := &plugin.Response{}
+	response.AddFile(output, string(content))
+
+	return response, nil
+}
+
+func (p *PathItem) SetOperation(method string, operation *Operation) bool {
+	switch strings.ToUpper(method) {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	case "TRACE":
+		p.Trace = operation
+	default:
+		return false
+	}
+	return true
+}
+
+// AddResponse adds responder method as operation response, methods with the same status are merged
+func AddResponse(operation *Operation, response restc.Response, componentNames map[string]string) {
+	status := plugin.ResponseStatus(response)
+
+	var schema *Schema
+	if len(response.Params) > 0 {
+		schema = TypeSchema(response.Params[0].Type, componentNames)
+	}
+
+	existing, ok := operation.Responses[status]
+	if !ok {
+		r := &Response{Description: response.Name}
+		if schema != nil {
+			r.Content = map[string]MediaType{"application/json": {Schema: schema}}
+		}
+		operation.Responses[status] = r
+		return
+	}
+
+	existing.Description += " | " + response.Name
+	if schema == nil {
+		return
+	}
+
+	if existing.Content == nil {
+		existing.Content = map[string]MediaType{"application/json": {Schema: schema}}
+		return
+	}
+
+	// schema of a single method could be oneOf itself, e.g. nullable reference, it is wrapped as a whole
+	current := existing.Content["application/json"].Schema
+	if !existing.merged {
+		current = &Schema{OneOf: []*Schema{current}}
+		existing.merged = true
+	}
+	current.OneOf = append(current.OneOf, schema)
+	existing.Content["application/json"] = MediaType{Schema: current}
+}
+
+// TypeSchema builds schema for the type identifier, declared types are referenced via components
+func TypeSchema(identifier string, componentNames map[string]string) *Schema {
+	if name, ok := componentNames[identifier]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return ConvertSchema(restc.NewSchemaFromIdentifier(identifier), componentNames)
+}
+
+func ConvertSchema(schema *restc.Schema, componentNames map[string]string) *Schema {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		ref := &Schema{}
+		if name, ok := componentNames[schema.Ref]; ok {
+			ref.Ref = "#/components/schemas/" + name
+		}
+
+		if schema.Nullable {
+			return &Schema{OneOf: []*Schema{ref, {Type: "null"}}}
+		}
+		return ref
+	}
+
+	var schemaType any
+	if schema.Nullable && schema.Type != "" {
+		schemaType = []string{schema.Type, "null"}
+	} else if schema.Type != "" {
+		schemaType = schema.Type
+	}
+
+	s := &Schema{
+		Type:                 schemaType,
+		Format:               schema.Format,
+		Items:                ConvertSchema(schema.Items, componentNames),
+		Required:             schema.Required,
+		AdditionalProperties: ConvertSchema(schema.AdditionalProperties, componentNames),
+	}
+
+	for _, as := range schema.AllOf {
+		s.AllOf = append(s.AllOf, ConvertSchema(as, componentNames))
+	}
+
+	if schema.Properties != nil {
+		s.Properties = orderedmap.New[string, *Schema]()
+		for p := schema.Properties.Oldest(); p != nil; p = p.Next() {
+			s.Properties.Set(p.Key, ConvertSchema(p.Value, componentNames))
+		}
+	}
+
+	return s
+}
+
+// ResetStyle drops JSON flow style from yaml nodes
+func ResetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		ResetStyle(n)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+	"github.com/tulinowpavel/restc/plugins/internal/plugintest"
+)
+
+func TestAddResponse(t *testing.T) {
+	componentNames := map[string]string{
+		"example.com/app/task Task":  "task.Task",
+		"example.com/app/task Error": "task.Error",
+	}
+
+	tests := []struct {
+		name      string
+		responses []restc.Response
+		want      string
+	}{
+		{
+			name: "single",
+			responses: []restc.Response{
+				{Name: "OK", Params: []restc.Parameter{{Name: "task", Type: "example.com/app/task Task"}}},
+			},
+			want: `{"200":{"description":"OK","content":{"application/json":{"schema":{"$ref":"#/components/schemas/task.Task"}}}}}`,
+		},
+		{
+			name: "nullable reference merged",
+			responses: []restc.Response{
+				{Name: "OK", Params: []restc.Parameter{{Name: "task", Type: "*example.com/app/task Task"}}},
+				{Name: "Failed", Params: []restc.Parameter{{Name: "err", Type: "example.com/app/task Error"}}},
+			},
+			want: `{"200":{"description":"OK | Failed","content":{"application/json":{"schema":{"oneOf":[` +
+				`{"oneOf":[{"$ref":"#/components/schemas/task.Task"},{"type":"null"}]},` +
+				`{"$ref":"#/components/schemas/task.Error"}]}}}}}`,
+		},
+		{
+			name: "three merged",
+			responses: []restc.Response{
+				{Name: "OK", Params: []restc.Parameter{{Name: "task", Type: "example.com/app/task Task"}}},
+				{Name: "Failed", Params: []restc.Parameter{{Name: "err", Type: "example.com/app/task Error"}}},
+				{Name: "Message", Params: []restc.Parameter{{Name: "message", Type: "string"}}},
+			},
+			want: `{"200":{"description":"OK | Failed | Message","content":{"application/json":{"schema":{"oneOf":[` +
+				`{"$ref":"#/components/schemas/task.Task"},{"$ref":"#/components/schemas/task.Error"},{"type":"string"}]}}}}}`,
+		},
+		{
+			name: "without body merged",
+			responses: []restc.Response{
+				{Name: "Empty"},
+				{Name: "OK", Params: []restc.Parameter{{Name: "task", Type: "*example.com/app/task Task"}}},
+			},
+			want: `{"200":{"description":"Empty | OK","content":{"application/json":{"schema":` +
+				`{"oneOf":[{"$ref":"#/components/schemas/task.Task"},{"type":"null"}]}}}}}`,
+		},
+		{
+			name: "different statuses",
+			responses: []restc.Response{
+				{Name: "Created", Annotations: map[string][]string{"@Status": {"201"}}, Params: []restc.Parameter{{Name: "task", Type: "example.com/app/task Task"}}},
+				{Name: "NotFound", Annotations: map[string][]string{"@Status": {"404"}}},
+			},
+			want: `{"201":{"description":"Created","content":{"application/json":{"schema":{"$ref":"#/components/schemas/task.Task"}}}},` +
+				`"404":{"description":"NotFound"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := &Operation{Responses: make(map[string]*Response)}
+			for _, response := range tt.responses {
+				AddResponse(operation, response, componentNames)
+			}
+
+			got, err := json.Marshal(operation.Responses)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	response, err := generate(&plugin.Request{PluginRequest: restc.PluginRequest{
+		Definitions: plugintest.Definitions(t),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics %+v", response.Diagnostics)
+	}
+
+	plugintest.Golden(t, response)
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+)
+
+// generator holds state of a single plugin request
+type generator struct {
+	definitions restc.Definitions
+
+	// typeNames and responderNames map type identifiers to TypeScript type names
+	typeNames, responderNames map[string]string
+}
+
+func main() {
+	plugin.Run(generate)
+}
+
+func generate(request *plugin.Request) (*plugin.Response, error) {
+	g := &generator{
+		definitions:    request.Definitions,
+		typeNames:      plugin.ShortNames(request.Definitions.Types, "_"),
+		responderNames: plugin.ShortNames(request.Definitions.Responders, "_"),
+	}
+
+	sb := strings.Builder{}
+
+	sb.WriteString("// Code generated with RESTc compiler's typescript plugin DO NOT EDIT.\n\n")
+	sb.WriteString(helpers)
+
+	for _, identifier := range plugin.SortedKeys(g.definitions.Types) {
+		g.WriteType(&sb, g.typeNames[identifier], g.definitions.Types[identifier].Schema)
+	}
+
+	for _, identifier := range plugin.SortedKeys(g.definitions.Responders) {
+		g.WriteResult(&sb, g.responderNames[identifier], g.definitions.Responders[identifier])
+	}
+
+	for _, controller := range request.Controllers() {
+		clientName := strings.TrimSuffix(controller.Name, "Controller") + "Client"
+
+		sb.WriteString("export class ")
+		sb.WriteString(clientName)
+		sb.WriteString(" {\n")
+		sb.WriteString("  constructor(\n")
+		sb.WriteString("    private readonly baseURL: string,\n")
+		sb.WriteString("    private readonly fetchFn: typeof fetch = globalThis.fetch.bind(globalThis),\n")
+		sb.WriteString("  ) {}\n\n")
+
+		for _, resource := range plugin.Resources(controller) {
+			g.WriteResourceMethod(&sb, controller, resource)
+		}
+
+		sb.WriteString("}\n\n")
+	}
+
+	response := &plugin.Response{}
+	response.AddFile(request.Option("file", "client.ts"), strings.TrimRight(sb.String(), "\n")+"\n")
+
+	return response, nil
+}
+
+func (g *generator) WriteType(sb *strings.Builder, name string, schema *restc.Schema) {
+	if schema != nil && schema.Type == "object" && schema.Properties != nil {
+		sb.WriteString("export interface ")
+		sb.WriteString(name)
+		sb.WriteString(" ")
+		sb.WriteString(g.ObjectType(schema, ""))
+		sb.WriteString("\n\n")
+		return
+	}
+
+	sb.WriteString("export type ")
+	sb.WriteString(name)
+	sb.WriteString(" = ")
+	sb.WriteString(g.SchemaType(schema, ""))
+	sb.WriteString(";\n\n")
+}
+
+// WriteResult writes discriminated union of responder methods, discriminated by method name and status
+func (g *generator) WriteResult(sb *strings.Builder, name string, responder restc.Responder) {
+	sb.WriteString("export type ")
+	sb.WriteString(name)
+	sb.WriteString("Result =")
+	if len(responder.Responses) == 0 {
+		sb.WriteString(" never;\n\n")
+		return
+	}
+	for _, response := range responder.Responses {
+		sb.WriteString("\n  | { response: \"")
+		sb.WriteString(response.Name)
+		sb.WriteString("\"; status: ")
+		sb.WriteString(plugin.ResponseStatus(response))
+		if len(response.Params) > 0 {
+			sb.WriteString("; body: ")
+			sb.WriteString(g.IdentifierType(response.Params[0].Type, "  "))
+		}
+		sb.WriteString(" }")
+	}
+	sb.WriteString(";\n\n")
+}
+
+func (g *generator) WriteResourceMethod(sb *strings.Builder, controller restc.Controller, resource restc.Resource) {
+	var responder *restc.Responder
+	var responderName string
+	var body *restc.Parameter
+
+	args := make([]string, 0, len(resource.Params))
+	for _, param := range resource.Params {
+		switch param.Source {
+		case restc.ParameterSourceContext:
+			continue
+		case restc.ParameterSourceResponder:
+			r := g.definitions.Responders[param.Type]
+			responder, responderName = &r, g.responderNames[param.Type]
+			continue
+		case restc.ParameterSourceBody:
+			p := param
+			body = &p
+		}
+
+		// pointers are nullable, undefined is accepted as well since optional args could not precede required ones
+		paramType := g.IdentifierType(param.Type, "    ")
+		if restc.IsPointerTypeIdentifier(param.Type) {
+			paramType += " | undefined"
+		}
+		args = append(args, param.Name+": "+paramType)
+	}
+	args = append(args, "init?: RequestInit")
+
+	resultType := "void"
+	if responder != nil {
+		resultType = responderName + "Result"
+	}
+
+	if resource.Summary != "" {
+		sb.WriteString("  /** ")
+		sb.WriteString(resource.Summary)
+		sb.WriteString(" */\n")
+	}
+
+	sb.WriteString("  async ")
+	sb.WriteString(LowerFirst(resource.Name))
+	sb.WriteString("(")
+	sb.WriteString(strings.Join(args, ", "))
+	sb.WriteString("): Promise<")
+	sb.WriteString(resultType)
+	sb.WriteString("> {\n")
+
+	// path with encoded path params
+	resourcePath := plugin.ReplacePathParams(plugin.FullPath(controller, resource), func(name string) string {
+		return "${encodeURIComponent(String(" + name + "))}"
+	})
+	sb.WriteString("    const url = resolveURL(this.baseURL, `")
+	sb.WriteString(resourcePath)
+	sb.WriteString("`);\n")
+
+	for _, param := range resource.Params {
+		if param.Source != restc.ParameterSourceQuery {
+			continue
+		}
+		sb.WriteString("    appendParam(url.searchParams, \"")
+		sb.WriteString(param.Name)
+		sb.WriteString("\", ")
+		sb.WriteString(param.Name)
+		sb.WriteString(");\n")
+	}
+
+	sb.WriteString("    const headers = new Headers(init?.headers);\n")
+	sb.WriteString("    headers.set(\"Accept\", \"application/json\");\n")
+	for _, param := range resource.Params {
+		if param.Source != restc.ParameterSourceHeader {
+			continue
+		}
+		headerName := param.Name
+		if metadata := strings.Fields(param.Metadata); len(metadata) > 0 {
+			headerName = metadata[0]
+		}
+		sb.WriteString("    appendParam(headers, \"")
+		sb.WriteString(headerName)
+		sb.WriteString("\", ")
+		sb.WriteString(param.Name)
+		sb.WriteString(");\n")
+	}
+	if body != nil {
+		sb.WriteString("    headers.set(\"Content-Type\", \"application/json\");\n")
+	}
+
+	sb.WriteString("    const response = await this.fetchFn(url, {\n")
+	sb.WriteString("      ...init,\n")
+	sb.WriteString("      method: \"")
+	sb.WriteString(strings.ToUpper(resource.Method))
+	sb.WriteString("\",\n")
+	sb.WriteString("      headers,\n")
+	if body != nil {
+		sb.WriteString("      body: JSON.stringify(")
+		sb.WriteString(body.Name)
+		sb.WriteString("),\n")
+	}
+	sb.WriteString("    });\n\n")
+
+	if responder == nil {
+		sb.WriteString("    if (!response.ok) {\n")
+		sb.WriteString("      throw new UnexpectedStatusError(response.status, await response.text());\n")
+		sb.WriteString("    }\n")
+		sb.WriteString("  }\n\n")
+		return
+	}
+
+	sb.WriteString("    switch (response.status) {\n")
+	statuses := make(map[string]bool)
+	for _, response := range responder.Responses {
+		status := plugin.ResponseStatus(response)
+		// responses with the same status could not be distinguished, first method wins
+		if statuses[status] {
+			continue
+		}
+		statuses[status] = true
+
+		sb.WriteString("      case ")
+		sb.WriteString(status)
+		sb.WriteString(":\n")
+		sb.WriteString("        return { response: \"")
+		sb.WriteString(response.Name)
+		sb.WriteString("\", status: ")
+		sb.WriteString(status)
+		if len(response.Params) > 0 {
+			sb.WriteString(", body: await response.json()")
+		}
+		sb.WriteString(" };\n")
+	}
+	sb.WriteString("      default:\n")
+	sb.WriteString("        throw new UnexpectedStatusError(response.status, await response.text());\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("  }\n\n")
+}
+
+const helpers = `export class UnexpectedStatusError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: string,
+  ) {
+    super(` + "`unexpected response status ${status}: ${body}`" + `);
+  }
+}
+
+function resolveURL(baseURL: string, path: string): URL {
+  return new URL(baseURL.replace(/\/+$/, "") + path, globalThis.location?.href);
+}
+
+function appendParam(target: { append(name: string, value: string): void }, name: string, value: unknown): void {
+  if (value === undefined || value === null) {
+    return;
+  }
+  if (Array.isArray(value)) {
+    value.forEach((v) => appendParam(target, name, v));
+    return;
+  }
+  target.append(name, value instanceof Date ? value.toISOString() : String(value));
+}
+
+`
+
+// SchemaType converts schema into TypeScript type expression
+func (g *generator) SchemaType(schema *restc.Schema, indent string) string {
+	if schema == nil {
+		return "unknown"
+	}
+
+	t := "unknown"
+
+	switch {
+	case schema.Ref != "":
+		if name, ok := g.typeNames[schema.Ref]; ok {
+			t = name
+		}
+	case len(schema.AllOf) > 0:
+		parts := make([]string, 0, len(schema.AllOf))
+		for _, s := range schema.AllOf {
+			parts = append(parts, g.SchemaType(s, indent))
+		}
+		t = strings.Join(parts, " & ")
+	case schema.Type == "string":
+		t = "string"
+	case schema.Type == "integer" || schema.Type == "number":
+		t = "number"
+	case schema.Type == "boolean":
+		t = "boolean"
+	case schema.Type == "array":
+		t = g.SchemaType(schema.Items, indent)
+		if strings.ContainsAny(t, "|&") {
+			t = "(" + t + ")"
+		}
+		t += "[]"
+	case schema.Type == "object" && schema.Properties != nil:
+		t = g.ObjectType(schema, indent)
+	case schema.Type == "object":
+		t = "Record<string, " + g.SchemaType(schema.AdditionalProperties, indent) + ">"
+	}
+
+	if schema.Nullable {
+		t += " | null"
+	}
+
+	return t
+}
+
+func (g *generator) ObjectType(schema *restc.Schema, indent string) string {
+	required := make(map[string]bool, len(schema.Required))
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("{\n")
+	for p := schema.Properties.Oldest(); p != nil; p = p.Next() {
+		sb.WriteString(indent)
+		sb.WriteString("  ")
+		sb.WriteString(PropertyName(p.Key))
+		if !required[p.Key] {
+			sb.WriteString("?")
+		}
+		sb.WriteString(": ")
+		sb.WriteString(g.SchemaType(p.Value, indent+"  "))
+		sb.WriteString(";\n")
+	}
+	sb.WriteString(indent)
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// IdentifierType converts Go type identifier into TypeScript type expression
+func (g *generator) IdentifierType(identifier string, indent string) string {
+	return g.SchemaType(restc.NewSchemaFromIdentifier(identifier), indent)
+}
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func PropertyName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	quoted, _ := json.Marshal(name)
+	return string(quoted)
+}
+
+func LowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
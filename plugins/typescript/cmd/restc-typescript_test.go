@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tulinowpavel/restc"
+	"github.com/tulinowpavel/restc/plugin"
+	"github.com/tulinowpavel/restc/plugins/internal/plugintest"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	response, err := generate(&plugin.Request{PluginRequest: restc.PluginRequest{
+		Definitions: plugintest.Definitions(t),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics %+v", response.Diagnostics)
+	}
+
+	plugintest.Golden(t, response)
+}
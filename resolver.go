@@ -3,22 +3,21 @@ package restc
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
+	"go/build"
 	"go/token"
 	"path/filepath"
 	"strings"
 )
 
 type TypeResolvingContext struct {
-	projectRoot string
-	module      string
-	packagePath string
-	imports     map[string]string
+	module         *Module
+	packagePath    string
+	imports        map[string]string
+	unnamedImports []string
 }
 
-func NewTypeResolvingContext(projectRoot, module, packageName string, imports []*ast.ImportSpec) TypeResolvingContext {
+func NewTypeResolvingContext(module *Module, packageName string, imports []*ast.ImportSpec) TypeResolvingContext {
 	trc := TypeResolvingContext{
-		projectRoot: projectRoot,
 		module:      module,
 		packagePath: packageName,
 		imports:     make(map[string]string),
@@ -29,17 +28,42 @@ func NewTypeResolvingContext(projectRoot, module, packageName string, imports []
 		if is.Name != nil {
 			trc.imports[is.Name.Name] = pv
 		} else {
-			pathParts := strings.Split(pv, "/")
-			trc.imports[pathParts[len(pathParts)-1]] = pv
+			trc.imports[ImportPathName(pv)] = pv
+			trc.unnamedImports = append(trc.unnamedImports, pv)
 		}
 	}
 
 	return trc
 }
 
+// ImportPath returns import path of the package alias,
+// package names of imports without alias are looked up in package clauses when they differ from import path
+func (trctx TypeResolvingContext) ImportPath(alias string) (string, bool) {
+	if importPath, ok := trctx.imports[alias]; ok {
+		return importPath, true
+	}
+
+	for _, importPath := range trctx.unnamedImports {
+		if name, err := trctx.module.PackageName(importPath); err == nil && name == alias {
+			trctx.imports[alias] = importPath
+			return importPath, true
+		}
+	}
+
+	return "", false
+}
+
 type TypeResolver struct {
+	cache *FileCache
 	// full/path/to/package TypeName
 	resolvedTypes map[string]*ResolvedType
+	// import path -> indexed package, all types of a package are indexed at once
+	packages map[string]indexedPackage
+}
+
+type indexedPackage struct {
+	dir   string
+	files []string
 }
 
 type ResolvedType struct {
@@ -47,41 +71,85 @@ type ResolvedType struct {
 	File             string
 	Name             string
 	Doc              *ast.CommentGroup
-	Type             ast.Node
+	Pos              token.Pos
+	Type             ast.Expr
 	ResolvingContext *TypeResolvingContext
 }
 
-func NewTypeResolver() TypeResolver {
+func NewTypeResolver(cache *FileCache) TypeResolver {
 	return TypeResolver{
+		cache:         cache,
 		resolvedTypes: make(map[string]*ResolvedType),
+		packages:      make(map[string]indexedPackage),
 	}
 }
 
 // ResolveIdentifierExpr resolves type expression into full qualified type name (with package and without package alias)
 //
 // format: full/path/to/package TypeName
-func (r *TypeResolver) ResolveIdentifierExpr(trctx TypeResolvingContext, expr ast.Expr) string {
+//
+// pointers, slices, arrays and maps are resolved into composite identifiers like []full/path/to/package TypeName
+func (r *TypeResolver) ResolveIdentifierExpr(trctx TypeResolvingContext, expr ast.Expr) (string, error) {
 	switch i := expr.(type) {
 	case *ast.Ident:
 		if IsPrimitive(i.Name) {
-			return i.Name
+			return i.Name, nil
 		}
-		return trctx.packagePath + " " + i.Name
+		return trctx.packagePath + " " + i.Name, nil
 	case *ast.SelectorExpr:
 		packageAlias := i.X.(*ast.Ident).Name
-		packagePath := trctx.imports[packageAlias]
-		return strings.TrimSpace(packagePath + " " + i.Sel.Name)
-	// case *ast.ArrayType:
-	// 	switch el := i.Elt.(type) {
-	// 	case *ast.Ident:
-	// 	case *ast.SelectorExpr:
-	// 		packageAlias := el.X.(*ast.Ident).Name
-	// 		packagePath := trctx.imports[packageAlias]
-	// 		return strings.TrimSpace(packagePath + " []" + el.Sel.Name)
-	// 	}
-	// 	return ""
+		packagePath, ok := trctx.ImportPath(packageAlias)
+		if !ok {
+			return "", fmt.Errorf("unknown package %s", packageAlias)
+		}
+		return packagePath + " " + i.Sel.Name, nil
+	case *ast.ParenExpr:
+		return r.ResolveIdentifierExpr(trctx, i.X)
+	case *ast.StarExpr:
+		elem, err := r.ResolveIdentifierExpr(trctx, i.X)
+		if err != nil {
+			return "", err
+		}
+		return "*" + elem, nil
+	case *ast.Ellipsis:
+		elem, err := r.ResolveIdentifierExpr(trctx, i.Elt)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *ast.ArrayType:
+		elem, err := r.ResolveIdentifierExpr(trctx, i.Elt)
+		if err != nil {
+			return "", err
+		}
+
+		if i.Len == nil {
+			return "[]" + elem, nil
+		}
+
+		length, ok := i.Len.(*ast.BasicLit)
+		if !ok {
+			return "", fmt.Errorf("array length must be a literal")
+		}
+		return "[" + length.Value + "]" + elem, nil
+	case *ast.MapType:
+		key, err := r.ResolveIdentifierExpr(trctx, i.Key)
+		if err != nil {
+			return "", err
+		}
+
+		value, err := r.ResolveIdentifierExpr(trctx, i.Value)
+		if err != nil {
+			return "", err
+		}
+		return "map[" + key + "]" + value, nil
+	case *ast.InterfaceType:
+		if i.Methods == nil || len(i.Methods.List) == 0 {
+			return "any", nil
+		}
+		return "", fmt.Errorf("anonymous interfaces are not supported")
 	default:
-		panic("unsupported ast node to resolve identifier")
+		return "", fmt.Errorf("unsupported type expression %T", expr)
 	}
 }
 
@@ -104,58 +172,85 @@ func (r *TypeResolver) ResolveType(trctx TypeResolvingContext, identifier string
 		return rt, nil
 	}
 
-	var rt *ResolvedType
+	if _, ok := r.packages[packageIdentifier]; !ok {
+		if err := r.indexPackage(trctx.module, packageIdentifier); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.resolvedTypes[normalizedIdentifier], nil
+}
 
-	if strings.HasPrefix(packageIdentifier, trctx.module) {
-		packagePath := filepath.Join(trctx.projectRoot, strings.TrimPrefix(strings.TrimPrefix(packageIdentifier, trctx.module), "/"))
+// indexPackage parses package files concurrently and indexes its type declarations
+func (r *TypeResolver) indexPackage(module *Module, packageIdentifier string) error {
+	packagePath, err := module.PackageDir(packageIdentifier)
+	if err != nil {
+		return err
+	}
 
-		files, err := filepath.Glob(packagePath + "/*.go")
-		if err != nil {
-			return nil, err
+	files, err := PackageFiles(packagePath)
+	if err != nil {
+		return err
+	}
+
+	parsed, errs := r.cache.ParseFiles(files)
+	for i, fast := range parsed {
+		if errs[i] != nil {
+			return fmt.Errorf("resolve type parse error: %w", errs[i])
 		}
 
-		for _, fn := range files {
-			fast, err := parser.ParseFile(token.NewFileSet(), fn, nil, parser.ParseComments)
-			if err != nil {
-				return nil, fmt.Errorf("resolve type parse error: %w", err)
-			}
+		fileCtx := NewTypeResolvingContext(module, packageIdentifier, fast.Imports)
 
-			ast.Inspect(fast, func(n ast.Node) bool {
-				switch node := n.(type) {
-				case *ast.File:
-					return true
-				case *ast.GenDecl:
-					for _, s := range node.Specs {
-						switch ts := s.(type) {
-						case *ast.TypeSpec:
-							r.resolvedTypes[packageIdentifier+" "+ts.Name.Name] = &ResolvedType{
-								Name:        ts.Name.Name,
-								File:        fn,
-								PackageName: packageIdentifier,
-								Doc:         node.Doc,
-								Type:        ts.Type,
-							}
+		ast.Inspect(fast, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.File:
+				return true
+			case *ast.GenDecl:
+				for _, s := range node.Specs {
+					switch ts := s.(type) {
+					case *ast.TypeSpec:
+						r.resolvedTypes[packageIdentifier+" "+ts.Name.Name] = &ResolvedType{
+							Name:             ts.Name.Name,
+							File:             files[i],
+							PackageName:      packageIdentifier,
+							Doc:              node.Doc,
+							Pos:              ts.Name.Pos(),
+							Type:             ts.Type,
+							ResolvingContext: &fileCtx,
 						}
 					}
 				}
-				return false
-			})
+			}
+			return false
+		})
+	}
+
+	r.packages[packageIdentifier] = indexedPackage{dir: packagePath, files: files}
+
+	return nil
+}
+
+// PackageFiles returns go files of the package directory, tests and files excluded by build constraints are skipped
+func PackageFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(dir + "/*.go")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(matches))
+	for _, fn := range matches {
+		if strings.HasSuffix(fn, "_test.go") {
+			continue
 		}
 
-		if t, ok := r.resolvedTypes[normalizedIdentifier]; ok {
-			rt = t
-		} else {
-			r.resolvedTypes[normalizedIdentifier] = nil
+		if match, err := build.Default.MatchFile(dir, filepath.Base(fn)); err != nil || !match {
+			continue
 		}
 
-	} else {
-		// TODO: resolve external packages
-		// do it like PATH
-		// look at go.mod for dependencies
-		return nil, fmt.Errorf("external packages is not supported currently")
+		files = append(files, fn)
 	}
 
-	return rt, nil
+	return files, nil
 }
 
 func (r *TypeResolver) AnalyzePackageFileAst(packageIdentifier string, fileAst *ast.File) {
@@ -0,0 +1,102 @@
+package restc
+
+import (
+	"go/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testModule writes module files into temporary directory and loads the module
+func testModule(t *testing.T, files map[string]string) *Module {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(root, name), content)
+	}
+
+	module, err := LoadModule(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return module
+}
+
+func writeTestFile(t *testing.T, filename, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveIdentifierExpr(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+	})
+
+	trctx := NewTypeResolvingContext(module, "example.com/app/task", nil)
+
+	tests := []struct {
+		expr       string
+		identifier string
+		err        bool
+	}{
+		{expr: "string", identifier: "string"},
+		{expr: "error", identifier: "error"},
+		{expr: "complex128", identifier: "complex128"},
+		{expr: "any", identifier: "any"},
+		{expr: "interface{}", identifier: "any"},
+		{expr: "Task", identifier: "example.com/app/task Task"},
+		{expr: "(Task)", identifier: "example.com/app/task Task"},
+		{expr: "interface{ Close() error }", err: true},
+		{expr: "func()", err: true},
+	}
+
+	r := NewTypeResolver(NewFileCache())
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identifier, err := r.ResolveIdentifierExpr(trctx, expr)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("error expected, got %q", identifier)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identifier != tt.identifier {
+				t.Errorf("got %q, want %q", identifier, tt.identifier)
+			}
+		})
+	}
+}
+
+func TestIsPrimitive(t *testing.T) {
+	for _, name := range []string{"bool", "string", "int", "uint8", "byte", "rune", "float64", "complex64", "complex128", "error", "any"} {
+		if !IsPrimitive(name) {
+			t.Errorf("%s must be primitive", name)
+		}
+		if PrimitiveSchema(name) == nil {
+			t.Errorf("%s must have primitive schema", name)
+		}
+	}
+
+	for _, identifier := range []string{"Task", "time Time", "[]string", "*error"} {
+		if IsPrimitive(identifier) {
+			t.Errorf("%s must not be primitive", identifier)
+		}
+	}
+}
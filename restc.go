@@ -1,11 +1,12 @@
 package restc
 
 import (
+	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"io/fs"
 	"log/slog"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
@@ -17,53 +18,161 @@ import (
 var pathParamRegex *regexp.Regexp = regexp.MustCompile(`\{([[:alnum:]]*)\}`)
 
 type RestCompilerAnalyzer struct {
+	analysis
+	cache        *FileCache
+	resolver     TypeResolver
+	projectFiles []string
+}
+
+// analysis collects definitions and diagnostics of the module files selected by the filter,
+// it is shared by AST and packages analyzers
+type analysis struct {
 	logger      *slog.Logger
-	moduleName  string
-	projectRoot string
-	filePattern *regexp.Regexp
-	resolver    TypeResolver
+	module      *Module
+	filter      FileFilter
+	fset        *token.FileSet
+	diagnostics []Diagnostic
 
 	Definitions Definitions
 }
 
-func NewRestCompilerAnalyzer(logger *slog.Logger, moduleName, projectRoot string, filePattern *regexp.Regexp) RestCompilerAnalyzer {
-	return RestCompilerAnalyzer{
+func newAnalysis(logger *slog.Logger, module *Module, filter FileFilter, fset *token.FileSet) analysis {
+	return analysis{
 		logger:      logger,
-		moduleName:  moduleName,
-		projectRoot: projectRoot,
-		filePattern: filePattern,
-		resolver:    NewTypeResolver(),
+		module:      module,
+		filter:      filter,
+		fset:        fset,
 		Definitions: NewDefinitions(),
 	}
 }
 
-func (r *RestCompilerAnalyzer) Analyze() {
-	filepath.WalkDir(r.projectRoot, func(path string, d fs.DirEntry, err error) error {
+func NewRestCompilerAnalyzer(logger *slog.Logger, module *Module, filter FileFilter) RestCompilerAnalyzer {
+	return NewRestCompilerAnalyzerWithCache(logger, module, filter, NewFileCache())
+}
+
+// NewRestCompilerAnalyzerWithCache creates analyzer reusing files parsed by previous analyses
+func NewRestCompilerAnalyzerWithCache(logger *slog.Logger, module *Module, filter FileFilter, cache *FileCache) RestCompilerAnalyzer {
+	return RestCompilerAnalyzer{
+		analysis: newAnalysis(logger, module, filter, cache.FileSet()),
+		cache:    cache,
+		resolver: NewTypeResolver(cache),
+	}
+}
+
+// FileSet returns file set shared between analyzer and type resolver
+func (r *RestCompilerAnalyzer) FileSet() *token.FileSet {
+	return r.fset
+}
+
+// ProjectFiles returns go files under the module root selected by the filter,
+// vendored dependencies, test data, hidden directories and nested modules are skipped
+func ProjectFiles(root string, filter FileFilter) ([]string, []Diagnostic) {
+	var files []string
+	var diagnostics []Diagnostic
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			r.logger.Error("walk dir error", "path", path, "error", err)
-			os.Exit(1)
+			diagnostics = append(diagnostics, ErrorDiagnostics(path, err)...)
+			return nil
 		}
 
-		modulePath := strings.TrimPrefix(path, r.projectRoot+"/")
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
 
-		if !r.filePattern.MatchString(modulePath) {
 			return nil
 		}
 
-		fast, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+		if filter.Match(filepath.ToSlash(strings.TrimPrefix(path, root+"/"))) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	return files, diagnostics
+}
+
+// Analyze walks project files and collects definitions, problems are returned as diagnostics
+func (r *RestCompilerAnalyzer) Analyze() []Diagnostic {
+	files, diagnostics := ProjectFiles(r.module.Root, r.filter)
+	r.diagnostics = append(r.diagnostics, diagnostics...)
+	r.projectFiles = files
+
+	// files are parsed concurrently, packages of the project files are parsed once and shared with the resolver
+	parsed, errs := r.cache.ParseFiles(files)
+
+	for i, path := range files {
+		modulePath := strings.TrimPrefix(path, r.module.Root+"/")
+
+		r.logger.Debug("analyze file", "file", modulePath)
+
+		fast, err := parsed[i], errs[i]
 		if err != nil {
-			r.logger.Error("cannot parse file", "file", path, "error", err)
-			os.Exit(1)
+			r.diagnostics = append(r.diagnostics, ErrorDiagnostics(path, err)...)
+			continue
 		}
 
 		fileName := filepath.Base(modulePath)
-		packagePath := r.moduleName + "/" + filepath.Dir(modulePath)
+		packagePath := r.module.Path
+		if dir := filepath.Dir(modulePath); dir != "." {
+			packagePath += "/" + filepath.ToSlash(dir)
+		}
 
 		r.AnalyzeFile(fast, packagePath, fileName)
+	}
 
-		return nil
-	})
+	return r.finish()
+}
+
+// Inputs returns files read by the last analysis with their content hashes
+func (r *RestCompilerAnalyzer) Inputs() AnalysisInputs {
+	inputs := AnalysisInputs{
+		ProjectFiles:   r.projectFiles,
+		Packages:       make(map[string][]string, len(r.resolver.packages)),
+		PackageClauses: maps.Clone(r.module.packageClauses),
+		Vendor:         r.module.vendor,
+		Hashes:         make(map[string]string),
+	}
+
+	files := slices.Clone(r.projectFiles)
+	for _, p := range r.resolver.packages {
+		inputs.Packages[p.dir] = p.files
+		files = append(files, p.files...)
+	}
+
+	for _, file := range files {
+		if hash, ok := r.cache.Hash(file); ok {
+			inputs.Hashes[file] = hash
+		}
+	}
+
+	// package clauses are read without the file cache
+	for _, file := range inputs.PackageClauses {
+		if _, ok := inputs.Hashes[file]; ok || file == "" {
+			continue
+		}
+		if hash, err := hashFile(file); err == nil {
+			inputs.Hashes[file] = hash
+		}
+	}
+
+	return inputs
+}
 
+// finish sets package aliases, imports and type references of collected definitions,
+// resources of receivers without @Controller annotation are reported
+func (r *analysis) finish() []Diagnostic {
 	packageAliases := make(map[string]string, 0)
 
 	reg := regexp.MustCompile(`[\/\.\-]+`)
@@ -73,14 +182,39 @@ func (r *RestCompilerAnalyzer) Analyze() {
 		packageIdentifier := parts[0]
 		alias := strings.ToLower(reg.ReplaceAllString(packageIdentifier, "_"))
 		packageAliases[packageIdentifier] = alias
+		ts.Package = packageIdentifier
 		ts.Alias = alias + "." + parts[1]
 		r.Definitions.Types[ti] = ts
 	}
 
+	for identifier, responder := range r.Definitions.Responders {
+		responder.Package, _, _ = strings.Cut(identifier, " ")
+		for i := range responder.Responses {
+			setTypeRefs(responder.Responses[i].Params)
+		}
+		r.Definitions.Responders[identifier] = responder
+	}
+
 	for name, c := range r.Definitions.Controllers {
+		if c.Package == "" {
+			// resources was declared, but receiver has no @Controller annotation
+			for _, resource := range c.Resources {
+				r.diagnostics = append(r.diagnostics, Diagnostic{
+					Severity: SeverityError,
+					Message:  "resource " + resource.Name + " receiver " + name + " is not annotated with @Controller",
+					File:     filepath.Join(r.module.Root, strings.TrimPrefix(strings.TrimPrefix(resource.Package, r.module.Path), "/"), resource.File),
+				})
+			}
+			delete(r.Definitions.Controllers, name)
+			continue
+		}
+
 		alias := strings.ToLower(reg.ReplaceAllString(c.Package, "_"))
 		packageAliases[c.Package] = alias
 		c.Alias = alias + "." + c.Name
+		for _, resource := range c.Resources {
+			setTypeRefs(resource.Params)
+		}
 		r.Definitions.Controllers[name] = c
 	}
 
@@ -88,9 +222,38 @@ func (r *RestCompilerAnalyzer) Analyze() {
 	for p, a := range packageAliases {
 		imports = append(imports, a+` `+`"`+p+`"`)
 	}
+	slices.Sort(imports)
 
 	r.Definitions.Imports = imports
 
+	return r.diagnostics
+}
+
+// setTypeRefs fills structured type references of params
+func setTypeRefs(params []Parameter) {
+	for i := range params {
+		params[i].TypeRef = NewTypeRef(params[i].Type)
+	}
+}
+
+// position returns definition position with file path relative to the module root
+func (r *analysis) position(pos token.Pos) *Position {
+	p := r.fset.Position(pos)
+
+	file := p.Filename
+	if rel, err := filepath.Rel(r.module.Root, p.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+		file = filepath.ToSlash(rel)
+	}
+
+	return &Position{File: file, Line: p.Line, Column: p.Column}
+}
+
+func (r *analysis) errorf(pos token.Pos, format string, args ...any) {
+	r.diagnostics = append(r.diagnostics, NewDiagnostic(SeverityError, r.fset.Position(pos), fmt.Sprintf(format, args...)))
+}
+
+func (r *analysis) warnf(pos token.Pos, format string, args ...any) {
+	r.diagnostics = append(r.diagnostics, NewDiagnostic(SeverityWarning, r.fset.Position(pos), fmt.Sprintf(format, args...)))
 }
 
 func (r *RestCompilerAnalyzer) AnalyzeFile(fast *ast.File, packagePath, fileName string) {
@@ -103,7 +266,7 @@ func (r *RestCompilerAnalyzer) AnalyzeFile(fast *ast.File, packagePath, fileName
 		switch node := n.(type) {
 		// parse imports
 		case *ast.File:
-			trctx = NewTypeResolvingContext(r.projectRoot, r.moduleName, packagePath, node.Imports)
+			trctx = NewTypeResolvingContext(r.module, packagePath, node.Imports)
 			return true
 		// Find controllers
 		case *ast.GenDecl:
@@ -114,42 +277,18 @@ func (r *RestCompilerAnalyzer) AnalyzeFile(fast *ast.File, packagePath, fileName
 					// case *ast.InterfaceType:
 					// definedTypes[ts.Name.Name] = its
 					case *ast.StructType:
-						// Find controllers
-						annotations := ParseAnnotations(node.Doc)
-						if controllerAnnotation, ok := annotations["@Controller"]; ok {
-							basePath := ""
-
-							if len(controllerAnnotation) > 0 {
-								basePath = controllerAnnotation[0]
-							}
-
-							r.Definitions.Controllers[ts.Name.Name] = Controller{
-								Package:   packagePath,
-								File:      path.Join(packagePath, fileName),
-								Name:      ts.Name.Name,
-								Base:      basePath,
-								Resources: make(map[string]Resource),
-							}
-						}
+						r.addController(node.Doc, ts.Name, packagePath, fileName)
 					}
 				}
 			}
 		// Find declared resources
 		case *ast.FuncDecl:
 			annotations := ParseAnnotations(node.Doc)
-			if _, ok := annotations["@Resource"]; !ok {
+			method, pathPattern, ok := r.resourceAnnotation(node, annotations)
+			if !ok {
 				return false
 			}
 
-			resourceAnnotation := strings.Split(annotations["@Resource"][0], " ")
-			if len(resourceAnnotation) < 2 {
-				r.logger.Error("incorrect resource annotation", "func", node.Name.Name)
-				return false
-			}
-
-			method := resourceAnnotation[0]
-			pathPattern := resourceAnnotation[1]
-
 			// paramsAnnotations := make(map[string]string, 0)
 			// for _, pa := range annotations["@Param"] {
 			// 	parts := strings.SplitN(pa, " ", 2)
@@ -164,122 +303,281 @@ func (r *RestCompilerAnalyzer) AnalyzeFile(fast *ast.File, packagePath, fileName
 			params := make([]Parameter, 0)
 
 			// find query and body params
-			if node.Type.Params.NumFields() > 1 {
-				for _, field := range node.Type.Params.List {
-					for _, fieldName := range field.Names {
-						paramTypeIdent := r.resolver.ResolveIdentifierExpr(trctx, field.Type)
-
-						if paramTypeIdent == "context Context" {
-							params = append(params, Parameter{
-								Source: ParameterSourceContext,
-								Name:   fieldName.Name,
-								Type:   paramTypeIdent,
-							})
-							continue
-						}
-
-						kind := ParameterSourceQuery
-
-						if !IsPrimitive(paramTypeIdent) {
-							paramType, err := r.resolver.ResolveType(trctx, paramTypeIdent)
-							if err != nil {
-								panic(err)
-							}
-
-							if paramType == nil {
-								panic("type " + paramTypeIdent + " not found")
-							}
-
-							switch paramType.Type.(type) {
-							case *ast.StructType:
-								if _, ok := r.Definitions.Types[paramTypeIdent]; !ok {
-									ts := r.ParseType(paramType)
-									r.Definitions.Types[paramTypeIdent] = ts
-								}
-								kind = ParameterSourceBody
-							case *ast.InterfaceType:
-								if _, ok := r.Definitions.Responders[paramTypeIdent]; !ok {
-									resp := r.ParseResponder(trctx, paramType)
-									r.Definitions.Responders[paramTypeIdent] = resp
-								}
-								kind = ParameterSourceResponder
-							}
-						}
-
-						params = append(params, Parameter{
-							Source: kind,
-							Name:   fieldName.Name,
-							Type:   paramTypeIdent,
-						})
+			for _, field := range node.Type.Params.List {
+				for _, fieldName := range field.Names {
+					paramTypeIdent, err := r.resolver.ResolveIdentifierExpr(trctx, field.Type)
+					if err != nil {
+						r.errorf(field.Type.Pos(), "cannot resolve type of param %s: %s", fieldName.Name, err)
+						return false
 					}
+
+					param, ok := r.resourceParam(fieldName.Name, paramTypeIdent, fieldName.Pos(), &astParamType{
+						r:          r,
+						trctx:      trctx,
+						identifier: paramTypeIdent,
+						pos:        field.Type.Pos(),
+					})
+					if !ok {
+						return false
+					}
+					params = append(params, param)
 				}
 			}
 
-			// path params
-			for _, pathParam := range pathParamRegex.FindAllStringSubmatch(pathPattern, -1) {
-				if len(pathParam) < 2 {
-					continue
-				}
+			r.addResource(node, annotations, ResolveResourceControllerName(node), packagePath, fileName, method, pathPattern, params)
+		}
 
-				paramIdx := slices.IndexFunc(params, func(p Parameter) bool {
-					return p.Name == pathParam[1]
-				})
+		return false
+	})
 
-				if paramIdx != -1 {
-					params[paramIdx].Source = ParameterSourcePath
-				}
+}
 
-			}
-			// end path params
-
-			// query, header, body params
-			if paramsAnnotations, ok := annotations["@Param"]; ok {
-				for _, annotation := range paramsAnnotations {
-					parts := strings.SplitN(annotation, " ", 3)
-					if len(parts) < 2 {
-						r.logger.Warn("incorrect annotation", "annotation", "@Param", "value", annotation)
-						continue
-					}
+// paramTypeKind tells how resource params of the named base type are read: structs are decoded from the body,
+// interfaces are responders and another named types are read from the query like builtin types
+type paramTypeKind int
 
-					name := parts[0]
-					source := parts[1]
+const (
+	// paramTypeOther are named types over primitives, slices and maps
+	paramTypeOther paramTypeKind = iota
+	paramTypeStruct
+	paramTypeInterface
+)
 
-					paramIdx := slices.IndexFunc(params, func(p Parameter) bool {
-						return p.Name == name
-					})
+// resourceParamType resolves and registers the resource param type for resourceParam,
+// it is implemented by walkers of the ast and go/types analyzers
+type resourceParamType interface {
+	// register adds schemas of named types referenced by the param type
+	register()
+	// base resolves the named base type and returns kind of its underlying type
+	base() (paramTypeKind, error)
+	// addBase adds schema of the resolved base type
+	addBase()
+	// responder parses the resolved base type as responder interface
+	responder() Responder
+}
 
-					if paramIdx < 0 {
-						r.logger.Error("unknown param annotation", "name", name)
-						os.Exit(1)
-					}
+// resourceParam classifies resource param by its type: context, responder interfaces and
+// structs or maps decoded from the body, another params are read from the query by default
+func (r *analysis) resourceParam(name, identifier string, pos token.Pos, t resourceParamType) (Parameter, bool) {
+	param := Parameter{
+		Source:   ParameterSourceQuery,
+		Name:     name,
+		Type:     identifier,
+		Position: r.position(pos),
+	}
 
-					params[paramIdx].Source = ParameterSource(source)
-					if len(parts) == 3 {
-						params[paramIdx].Metadata = parts[2]
-					}
-				}
+	if identifier == "context Context" {
+		param.Source = ParameterSourceContext
+		return param, true
+	}
+
+	// maps could be decoded only from body
+	if IsMapTypeIdentifier(identifier) {
+		param.Source = ParameterSourceBody
+		t.register()
+		return param, true
+	}
+
+	baseIdentifier := BaseTypeIdentifier(identifier)
+	if IsPrimitive(baseIdentifier) || WellKnownSchema(baseIdentifier) != nil {
+		return param, true
+	}
+
+	kind, err := t.base()
+	if err != nil {
+		r.errorf(pos, "cannot resolve type %s of param %s: %s", baseIdentifier, name, err)
+		return Parameter{}, false
+	}
+
+	switch kind {
+	case paramTypeStruct:
+		t.addBase()
+		param.Source = ParameterSourceBody
+	case paramTypeInterface:
+		if IsCompositeTypeIdentifier(identifier) {
+			r.errorf(pos, "responder param %s must be an interface value", name)
+			return Parameter{}, false
+		}
+
+		if _, ok := r.Definitions.Responders[identifier]; !ok {
+			r.Definitions.Responders[identifier] = t.responder()
+		}
+		param.Source = ParameterSourceResponder
+	default:
+		t.addBase()
+	}
+
+	return param, true
+}
+
+// addTypeSchema adds schema of the named type into definitions if it is not added yet
+func (r *analysis) addTypeSchema(identifier, name string, parse func() TypeSchema) {
+	if _, ok := r.Definitions.Types[identifier]; ok {
+		return
+	}
+
+	// placeholder breaks recursion for self referencing types
+	r.Definitions.Types[identifier] = TypeSchema{Name: name}
+	r.Definitions.Types[identifier] = parse()
+}
+
+// addController registers struct type annotated with @Controller
+func (r *analysis) addController(doc *ast.CommentGroup, name *ast.Ident, packagePath, fileName string) {
+	annotations := ParseAnnotations(doc)
+	if controllerAnnotation, ok := annotations["@Controller"]; ok {
+		basePath := ""
+
+		if len(controllerAnnotation) > 0 {
+			basePath = controllerAnnotation[0]
+		}
+
+		resources := make(map[string]Resource)
+		// resources could be found before controller in another file
+		if c, ok := r.Definitions.Controllers[name.Name]; ok {
+			resources = c.Resources
+		}
+
+		r.Definitions.Controllers[name.Name] = Controller{
+			Package:   packagePath,
+			File:      path.Join(packagePath, fileName),
+			Name:      name.Name,
+			Base:      basePath,
+			Resources: resources,
+			Position:  r.position(name.Pos()),
+		}
+	}
+}
+
+// resourceAnnotation returns method and path pattern of the @Resource annotation,
+// malformed annotations are reported
+func (r *analysis) resourceAnnotation(node *ast.FuncDecl, annotations map[string][]string) (string, string, bool) {
+	if _, ok := annotations["@Resource"]; !ok {
+		return "", "", false
+	}
+
+	if node.Recv == nil || len(annotations["@Resource"]) == 0 {
+		r.errorf(node.Pos(), "incorrect resource annotation on %s: method with @Resource METHOD /path expected", node.Name.Name)
+		return "", "", false
+	}
+
+	resourceAnnotation := strings.Fields(annotations["@Resource"][0])
+	if len(resourceAnnotation) < 2 {
+		r.errorf(node.Pos(), "incorrect resource annotation on %s: @Resource METHOD /path expected", node.Name.Name)
+		return "", "", false
+	}
+
+	return resourceAnnotation[0], resourceAnnotation[1], true
+}
+
+// addResource applies path params and @Param annotations to resource params and registers resource in its controller
+func (r *analysis) addResource(node *ast.FuncDecl, annotations map[string][]string, controllerName, packagePath, fileName, method, pathPattern string, params []Parameter) {
+	r.checkAnnotations(node.Doc, node.Name.Name, resourceAnnotations)
+
+	// path params
+	for _, pathParam := range pathParamRegex.FindAllStringSubmatch(pathPattern, -1) {
+		if len(pathParam) < 2 {
+			continue
+		}
+
+		paramIdx := slices.IndexFunc(params, func(p Parameter) bool {
+			return p.Name == pathParam[1]
+		})
+
+		if paramIdx != -1 {
+			params[paramIdx].Source = ParameterSourcePath
+		}
+
+	}
+	// end path params
+
+	// query, header, body params
+	if paramsAnnotations, ok := annotations["@Param"]; ok {
+		for _, annotation := range paramsAnnotations {
+			parts := strings.SplitN(annotation, " ", 3)
+			if len(parts) < 2 {
+				r.warnf(node.Pos(), "incorrect @Param annotation %q: @Param name Source [metadata] expected", annotation)
+				continue
 			}
-			// end query, header, body params
 
-			// FIXME: or use full name with package ???
-			// TODO: summary, details and tags annotation
-			// definitions.Controllers[node.Name.Name] =
+			name := parts[0]
+			source := parts[1]
+
+			paramIdx := slices.IndexFunc(params, func(p Parameter) bool {
+				return p.Name == name
+			})
 
-			controllerName := ResolveResourceControllerName(node)
-			r.Definitions.Controllers[controllerName].Resources[node.Name.Name] = Resource{
-				Package: packagePath,
-				File:    fileName,
+			if paramIdx < 0 {
+				r.errorf(node.Pos(), "@Param annotation refers to unknown param %s of %s", name, node.Name.Name)
+				continue
+			}
+
+			switch ParameterSource(source) {
+			case ParameterSourceHeader, ParameterSourcePath, ParameterSourceQuery, ParameterSourceBody:
+			default:
+				r.errorf(node.Pos(), "unknown source %s of param %s", source, name)
+				continue
+			}
 
-				Name:   node.Name.Name,
-				Method: method,
-				Path:   pathPattern,
-				Params: params,
+			params[paramIdx].Source = ParameterSource(source)
+			if len(parts) == 3 {
+				params[paramIdx].Metadata = parts[2]
 			}
 		}
+	}
+	// end query, header, body params
 
-		return false
-	})
+	// FIXME: or use full name with package ???
+	// definitions.Controllers[node.Name.Name] =
 
+	summary := strings.Join(annotations["@Summary"], " ")
+	if summary == "" {
+		summary = DocSynopsis(node.Doc)
+	}
+
+	if _, ok := r.Definitions.Controllers[controllerName]; !ok {
+		r.Definitions.Controllers[controllerName] = Controller{
+			Name:      controllerName,
+			Resources: make(map[string]Resource),
+		}
+	}
+
+	r.Definitions.Controllers[controllerName].Resources[node.Name.Name] = Resource{
+		Package: packagePath,
+		File:    fileName,
+
+		Name:   node.Name.Name,
+		Method: method,
+		Path:   pathPattern,
+		Params: params,
+
+		Summary: summary,
+		Details: strings.Join(annotations["@Details"], "\n"),
+		Tags:    annotations["@Tag"],
+
+		Position: r.position(node.Name.Pos()),
+	}
+}
+
+// resourceAnnotations are annotations of resource methods, another ones are likely misspelled
+var resourceAnnotations = []string{"@Resource", "@Param", "@Summary", "@Details", "@Tag"}
+
+// checkAnnotations reports annotations of the doc comment which are not known
+func (r *analysis) checkAnnotations(doc *ast.CommentGroup, name string, known []string) {
+	if doc == nil {
+		return
+	}
+
+	for _, c := range doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		annotation, _, _ := strings.Cut(line, " ")
+		if !slices.Contains(known, annotation) {
+			r.warnf(c.Pos(), "unknown annotation %s of %s", annotation, name)
+		}
+	}
 }
 
 func ParseAnnotations(comments *ast.CommentGroup) map[string][]string {
@@ -306,6 +604,37 @@ func ParseAnnotations(comments *ast.CommentGroup) map[string][]string {
 	return annotations
 }
 
+// DocSynopsis returns first sentence of the doc comment ignoring annotation lines
+func DocSynopsis(comments *ast.CommentGroup) string {
+	if comments == nil {
+		return ""
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(comments.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "@") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	text := strings.Join(lines, " ")
+	if idx := strings.Index(text, ". "); idx >= 0 {
+		text = text[:idx+1]
+	}
+
+	return text
+}
+
 func ResolveResourceControllerName(node *ast.FuncDecl) string {
 	switch t := node.Recv.List[0].Type.(type) {
 	case *ast.StarExpr:
@@ -316,40 +645,34 @@ func ResolveResourceControllerName(node *ast.FuncDecl) string {
 	return ""
 }
 
-func (r *RestCompilerAnalyzer) ParseResponder(trctx TypeResolvingContext, resolvedType *ResolvedType) Responder {
-	// TODO: implement me
-	// TODO: analyze methods and inputs
+func (r *RestCompilerAnalyzer) ParseResponder(resolvedType *ResolvedType) Responder {
+	trctx := *resolvedType.ResolvingContext
 
 	responses := make([]Response, 0)
 
 	i := resolvedType.Type.(*ast.InterfaceType)
 	for _, m := range i.Methods.List {
+		mf, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			r.warnf(m.Pos(), "embedded interfaces are not supported in responder %s", resolvedType.Name)
+			continue
+		}
 
-		mf := m.Type.(*ast.FuncType)
 		params := make([]Parameter, 0)
 
 		for _, mfp := range mf.Params.List {
 			for _, mfpn := range mfp.Names {
-				fullTypeName := r.resolver.ResolveIdentifierExpr(trctx, mfp.Type)
-				rt, err := r.resolver.ResolveType(trctx, fullTypeName)
+				fullTypeName, err := r.resolver.ResolveIdentifierExpr(trctx, mfp.Type)
 				if err != nil {
-					panic("cannot resolve responder type")
-				}
-
-				if rt == nil {
-					panic("resolving type for responder not found: " + fullTypeName)
-				}
-
-				// TODO: add to types if not exists
-
-				if _, ok := r.Definitions.Types[fullTypeName]; !ok {
-					ts := r.ParseType(rt)
-					r.Definitions.Types[fullTypeName] = ts
+					r.errorf(mfp.Type.Pos(), "cannot resolve type of responder %s param %s: %s", resolvedType.Name, mfpn.Name, err)
+					continue
 				}
+				r.RegisterType(trctx, fullTypeName, mfp.Type.Pos())
 
 				params = append(params, Parameter{
-					Type: fullTypeName,
-					Name: mfpn.Name,
+					Type:     fullTypeName,
+					Name:     mfpn.Name,
+					Position: r.position(mfpn.Pos()),
 				})
 			}
 		}
@@ -358,17 +681,157 @@ func (r *RestCompilerAnalyzer) ParseResponder(trctx TypeResolvingContext, resolv
 			Name:        m.Names[0].Name,
 			Annotations: ParseAnnotations(m.Doc),
 			Params:      params,
+			Position:    r.position(m.Names[0].Pos()),
 		})
 	}
 	return Responder{
 		Name:      resolvedType.Name,
 		Responses: responses,
+		Position:  r.position(resolvedType.Pos),
+	}
+}
+
+// astParamType resolves resource param types with the type resolver
+type astParamType struct {
+	r          *RestCompilerAnalyzer
+	trctx      TypeResolvingContext
+	identifier string
+	pos        token.Pos
+	resolved   *ResolvedType
+}
+
+func (t *astParamType) register() {
+	t.r.RegisterType(t.trctx, t.identifier, t.pos)
+}
+
+func (t *astParamType) base() (paramTypeKind, error) {
+	rt, err := t.r.resolver.ResolveType(t.trctx, BaseTypeIdentifier(t.identifier))
+	if err != nil {
+		return 0, err
+	}
+	if rt == nil {
+		return 0, fmt.Errorf("type not found")
+	}
+	t.resolved = rt
+
+	switch rt.Type.(type) {
+	case *ast.StructType:
+		return paramTypeStruct, nil
+	case *ast.InterfaceType:
+		return paramTypeInterface, nil
+	}
+	return paramTypeOther, nil
+}
+
+func (t *astParamType) addBase() {
+	t.r.AddType(BaseTypeIdentifier(t.identifier), t.resolved)
+}
+
+func (t *astParamType) responder() Responder {
+	return t.r.ParseResponder(t.resolved)
+}
+
+// RegisterType resolves named type and adds its schema into definitions,
+// primitive and well known types are skipped, composite types register their elements
+func (r *RestCompilerAnalyzer) RegisterType(trctx TypeResolvingContext, identifier string, pos token.Pos) {
+	if IsMapTypeIdentifier(identifier) {
+		r.RegisterType(trctx, MapKeyTypeIdentifier(identifier), pos)
+		r.RegisterType(trctx, ElemTypeIdentifier(identifier), pos)
+		return
 	}
+
+	if IsCompositeTypeIdentifier(identifier) {
+		r.RegisterType(trctx, ElemTypeIdentifier(identifier), pos)
+		return
+	}
+
+	if IsPrimitive(identifier) || WellKnownSchema(identifier) != nil {
+		return
+	}
+
+	if _, ok := r.Definitions.Types[identifier]; ok {
+		return
+	}
+
+	rt, err := r.resolver.ResolveType(trctx, identifier)
+	if err != nil {
+		r.errorf(pos, "cannot resolve type %s: %s", identifier, err)
+		return
+	}
+
+	if rt == nil {
+		r.errorf(pos, "type %s not found", identifier)
+		return
+	}
+
+	r.AddType(identifier, rt)
+}
+
+// AddType parses resolved type and adds its schema into definitions if it is not added yet
+func (r *RestCompilerAnalyzer) AddType(identifier string, resolvedType *ResolvedType) {
+	r.addTypeSchema(identifier, resolvedType.Name, func() TypeSchema {
+		return r.ParseType(resolvedType)
+	})
 }
 
 func (r *RestCompilerAnalyzer) ParseType(resolvedType *ResolvedType) TypeSchema {
-	// TODO: implement me
-	return TypeSchema{
-		Name: resolvedType.Name,
+	trctx := *resolvedType.ResolvingContext
+
+	ts := TypeSchema{
+		Name:     resolvedType.Name,
+		Position: r.position(resolvedType.Pos),
+		Schema: NewSchemaFromNode(resolvedType.Type, func(expr ast.Expr) string {
+			identifier, err := r.resolver.ResolveIdentifierExpr(trctx, expr)
+			if err != nil {
+				r.errorf(expr.Pos(), "cannot resolve type of %s field: %s", resolvedType.Name, err)
+				return ""
+			}
+			r.RegisterType(trctx, identifier, expr.Pos())
+			return identifier
+		}, func(expr ast.Expr) bool {
+			return r.isStructType(trctx, expr)
+		}),
+	}
+
+	// types declared over named types inherit their underlying type, the named type is registered by the schema
+	switch expr := resolvedType.Type.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		if ident, ok := expr.(*ast.Ident); ok && IsPrimitive(ident.Name) {
+			ts.Underlying = BasicTypeName(ident.Name)
+		} else if identifier, err := r.resolver.ResolveIdentifierExpr(trctx, expr); err == nil {
+			ts.Underlying = r.Definitions.Types[identifier].Underlying
+		}
+	}
+
+	return ts
+}
+
+// isStructType reports whether the type expression is a struct or a named type declared over a struct,
+// well known types are encoded as values and they are not structs for the schema
+func (r *RestCompilerAnalyzer) isStructType(trctx TypeResolvingContext, expr ast.Expr) bool {
+	seen := make(map[string]bool)
+	for {
+		switch e := expr.(type) {
+		case *ast.StructType:
+			return true
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.Ident, *ast.SelectorExpr:
+		default:
+			return false
+		}
+
+		identifier, err := r.resolver.ResolveIdentifierExpr(trctx, expr)
+		if err != nil || IsPrimitive(identifier) || WellKnownSchema(identifier) != nil || seen[identifier] {
+			return false
+		}
+		seen[identifier] = true
+
+		rt, err := r.resolver.ResolveType(trctx, identifier)
+		if err != nil || rt == nil {
+			return false
+		}
+		trctx, expr = *rt.ResolvingContext, rt.Type
 	}
 }
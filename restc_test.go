@@ -0,0 +1,424 @@
+package restc
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestAnalyzePredeclaredFieldTypes(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"api/api.go": `package api
+
+import "context"
+
+// @Controller /api
+type TaskController struct{}
+
+type Result struct {
+	Err   error      ` + "`json:\"err\"`" + `
+	Value complex128 ` + "`json:\"value\"`" + `
+}
+
+// @Resource POST /tasks
+func (c *TaskController) CreateTask(ctx context.Context, body Result) error {
+	return nil
+}
+`,
+	})
+
+	filter, _ := NewFileFilter(nil, nil)
+	analyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+	if diagnostics := analyzer.Analyze(); len(diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", diagnostics)
+	}
+
+	ts, ok := analyzer.Definitions.Types["example.com/app/api Result"]
+	if !ok {
+		t.Fatal("Result type is not registered")
+	}
+
+	schema, _ := json.Marshal(ts.Schema)
+	if want := `{"type":"object","required":["err","value"],"properties":{"err":{},"value":{}}}`; string(schema) != want {
+		t.Errorf("got schema %s, want %s", schema, want)
+	}
+}
+
+func TestAnalyzeUnderlyingTypes(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"api/api.go": `package api
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	Priority int8
+	Port     uint16
+	Offset   uint64
+	Mask     byte
+	Letter   rune
+	Status   string
+	Ratio    float32
+	Flag     bool
+	Level    Priority
+	Since    time.Time
+	Wave     complex64
+)
+
+type Filter struct {
+	Priority Priority ` + "`json:\"priority\"`" + `
+	Port     Port     ` + "`json:\"port\"`" + `
+	Offset   Offset   ` + "`json:\"offset\"`" + `
+	Mask     Mask     ` + "`json:\"mask\"`" + `
+	Letter   Letter   ` + "`json:\"letter\"`" + `
+	Status   Status   ` + "`json:\"status\"`" + `
+	Ratio    Ratio    ` + "`json:\"ratio\"`" + `
+	Flag     Flag     ` + "`json:\"flag\"`" + `
+	Level    Level    ` + "`json:\"level\"`" + `
+	Since    Since    ` + "`json:\"since\"`" + `
+	Wave     Wave     ` + "`json:\"-\"`" + `
+}
+
+// @Controller /api
+type TaskController struct{}
+
+// @Resource POST /tasks
+func (c *TaskController) Find(ctx context.Context, wave Wave, body Filter) error {
+	return nil
+}
+`,
+	})
+
+	want := map[string]string{
+		"Priority": "int8",
+		"Port":     "uint16",
+		"Offset":   "uint64",
+		"Mask":     "uint8",
+		"Letter":   "int32",
+		"Status":   "string",
+		"Ratio":    "float32",
+		"Flag":     "bool",
+		"Level":    "int8",
+		"Since":    "",
+		"Wave":     "complex64",
+		"Filter":   "",
+	}
+
+	filter, _ := NewFileFilter(nil, nil)
+	astAnalyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+	packagesAnalyzer := NewPackagesAnalyzer(testLogger(), module, filter)
+
+	analyzers := map[AnalysisMode]func() ([]Diagnostic, Definitions){
+		AnalysisAST: func() ([]Diagnostic, Definitions) {
+			return astAnalyzer.Analyze(), astAnalyzer.Definitions
+		},
+		AnalysisPackages: func() ([]Diagnostic, Definitions) {
+			return packagesAnalyzer.Analyze(), packagesAnalyzer.Definitions
+		},
+	}
+
+	for mode, analyze := range analyzers {
+		t.Run(string(mode), func(t *testing.T) {
+			diagnostics, definitions := analyze()
+			if HasErrors(diagnostics) {
+				t.Fatalf("unexpected diagnostics: %v", diagnostics)
+			}
+
+			for name, underlying := range want {
+				ts, ok := definitions.Types["example.com/app/api "+name]
+				if !ok {
+					t.Errorf("type %s is not registered", name)
+					continue
+				}
+				if ts.Underlying != underlying {
+					t.Errorf("type %s underlying %q, want %q", name, ts.Underlying, underlying)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeEmbeddedFields(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"api/api.go": `package api
+
+import (
+	"context"
+	"time"
+)
+
+type Base struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// Owned is declared over struct, its fields are promoted as well
+type Owned Base
+
+type Tags []string
+
+type labels []string
+
+type Item struct {
+	Base
+	*Owned
+	Tags
+	labels
+	time.Time
+}
+
+// @Controller /api
+type ItemController struct{}
+
+// @Resource POST /items
+func (c *ItemController) Create(ctx context.Context, body Item) error {
+	return nil
+}
+`,
+	})
+
+	// only structs are flattened, another embedded types are fields named after the type
+	want := `{"allOf":[{"$ref":"example.com/app/api Base"},{"$ref":"example.com/app/api Owned","nullable":true},` +
+		`{"type":"object","required":["Tags","Time"],"properties":{"Tags":{"$ref":"example.com/app/api Tags"},"Time":{"type":"string","format":"date-time"}}}]}`
+
+	filter, _ := NewFileFilter(nil, nil)
+	astAnalyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+	packagesAnalyzer := NewPackagesAnalyzer(testLogger(), module, filter)
+
+	analyzers := map[AnalysisMode]func() ([]Diagnostic, Definitions){
+		AnalysisAST: func() ([]Diagnostic, Definitions) {
+			return astAnalyzer.Analyze(), astAnalyzer.Definitions
+		},
+		AnalysisPackages: func() ([]Diagnostic, Definitions) {
+			return packagesAnalyzer.Analyze(), packagesAnalyzer.Definitions
+		},
+	}
+
+	for mode, analyze := range analyzers {
+		t.Run(string(mode), func(t *testing.T) {
+			diagnostics, definitions := analyze()
+			if HasErrors(diagnostics) {
+				t.Fatalf("unexpected diagnostics: %v", diagnostics)
+			}
+
+			got, err := json.Marshal(definitions.Types["example.com/app/api Item"].Schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("got  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDocSynopsis(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{name: "no doc", want: ""},
+		{name: "single sentence", doc: "// Get returns the task.", want: "Get returns the task."},
+		{name: "without period", doc: "// Get returns the task", want: "Get returns the task"},
+		{name: "first sentence", doc: "// Get returns the task. Archived tasks are returned too.", want: "Get returns the task."},
+		{
+			name: "multi-line sentence",
+			doc:  "// Get returns the task\n// with its labels. Archived tasks are returned too.",
+			want: "Get returns the task with its labels.",
+		},
+		{
+			name: "first paragraph",
+			doc:  "// Get returns the task\n//\n// Archived tasks are returned too.",
+			want: "Get returns the task",
+		},
+		{
+			name: "annotations are skipped",
+			doc:  "// @Resource GET /tasks/{id}\n// Get returns the task.\n// @Tag tasks",
+			want: "Get returns the task.",
+		},
+		{name: "only annotations", doc: "// @Resource GET /tasks/{id}", want: ""},
+		{name: "period inside sentence", doc: "// Get returns v1.2 task.", want: "Get returns v1.2 task."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "doc.go", "package doc\n\n"+tt.doc+"\nfunc Get() {}\n", parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := DocSynopsis(f.Decls[0].(*ast.FuncDecl).Doc); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeResourceDocs(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"api/api.go": `package api
+
+import "context"
+
+// @Controller /api
+type TaskController struct{}
+
+// Get returns the task
+// with its labels. Archived tasks are returned too.
+//
+// @Resource GET /tasks/{id}
+// @Tag tasks
+// @Tag public
+func (c *TaskController) Get(ctx context.Context, id string) error {
+	return nil
+}
+
+// List returns tasks. It is replaced by the summary annotation.
+//
+// @Resource GET /tasks
+// @Summary Lists tasks
+// @Summary of the organization
+// @Details Tasks are sorted by creation time.
+// @Details Archived tasks are skipped.
+func (c *TaskController) List(ctx context.Context) error {
+	return nil
+}
+
+// @Resource DELETE /tasks/{id}
+func (c *TaskController) Delete(ctx context.Context, id string) error {
+	return nil
+}
+`,
+	})
+
+	want := map[string]Resource{
+		"Get": {Summary: "Get returns the task with its labels.", Tags: []string{"tasks", "public"}},
+		"List": {
+			Summary: "Lists tasks of the organization",
+			Details: "Tasks are sorted by creation time.\nArchived tasks are skipped.",
+		},
+		"Delete": {},
+	}
+
+	filter, _ := NewFileFilter(nil, nil)
+	astAnalyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+	packagesAnalyzer := NewPackagesAnalyzer(testLogger(), module, filter)
+
+	analyzers := map[AnalysisMode]func() ([]Diagnostic, Definitions){
+		AnalysisAST: func() ([]Diagnostic, Definitions) {
+			return astAnalyzer.Analyze(), astAnalyzer.Definitions
+		},
+		AnalysisPackages: func() ([]Diagnostic, Definitions) {
+			return packagesAnalyzer.Analyze(), packagesAnalyzer.Definitions
+		},
+	}
+
+	for mode, analyze := range analyzers {
+		t.Run(string(mode), func(t *testing.T) {
+			diagnostics, definitions := analyze()
+			if HasErrors(diagnostics) {
+				t.Fatalf("unexpected diagnostics: %v", diagnostics)
+			}
+
+			for name, w := range want {
+				r := definitions.Controllers["TaskController"].Resources[name]
+				if r.Summary != w.Summary || r.Details != w.Details || !slices.Equal(r.Tags, w.Tags) {
+					t.Errorf("resource %s summary %q, details %q, tags %q, want %q, %q, %q", name, r.Summary, r.Details, r.Tags, w.Summary, w.Details, w.Tags)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeDiagnostics(t *testing.T) {
+	module := testModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"api/api.go": `package api
+
+import "context"
+
+// @Controller /api
+type TaskController struct{}
+
+// @Resource GET
+func (c *TaskController) Broken(ctx context.Context) error {
+	return nil
+}
+
+// @Resource GET /tasks/{id}
+// @Tags tasks
+// @Param verbose
+// @Param missing Query
+// @Param id Cookie
+func (c *TaskController) Get(ctx context.Context, id string, verbose bool) error {
+	return nil
+}
+
+// @Resource POST /tasks
+func (c *TaskController) Create(ctx context.Context, body Missing) error {
+	return nil
+}
+`,
+		"broken/broken.go": "package broken\n\nvar x = )\n",
+	})
+
+	// problems of all files are reported in one run
+	annotations := []string{
+		"api/api.go:9:1: error: incorrect resource annotation on Broken: @Resource METHOD /path expected",
+		"api/api.go:14:1: warning: unknown annotation @Tags of Get",
+		`api/api.go:18:1: warning: incorrect @Param annotation "verbose": @Param name Source [metadata] expected`,
+		"api/api.go:18:1: error: @Param annotation refers to unknown param missing of Get",
+		"api/api.go:18:1: error: unknown source Cookie of param id",
+	}
+	want := map[AnalysisMode][]string{
+		AnalysisAST: append(slices.Clone(annotations),
+			"api/api.go:23:54: error: cannot resolve type example.com/app/api Missing of param body: type not found",
+			"broken/broken.go:3:9: error: expected operand, found ')'",
+		),
+		AnalysisPackages: append([]string{"api/api.go:23:59: warning: undefined: Missing"}, append(slices.Clone(annotations),
+			"api/api.go:23:54: error: cannot resolve type of param body: type is not defined or has errors",
+			"broken/broken.go:3:9: error: expected operand, found ')'",
+			"broken/broken.go:3:11: error: expected ';', found 'EOF'",
+		)...),
+	}
+
+	filter, _ := NewFileFilter(nil, nil)
+	astAnalyzer := NewRestCompilerAnalyzer(testLogger(), module, filter)
+	packagesAnalyzer := NewPackagesAnalyzer(testLogger(), module, filter)
+
+	analyzers := map[AnalysisMode]func() []Diagnostic{
+		AnalysisAST:      astAnalyzer.Analyze,
+		AnalysisPackages: packagesAnalyzer.Analyze,
+	}
+
+	for mode, analyze := range analyzers {
+		t.Run(string(mode), func(t *testing.T) {
+			got := make([]string, 0)
+			for _, d := range analyze() {
+				d.File = strings.TrimPrefix(d.File, module.Root+string(filepath.Separator))
+				got = append(got, d.String())
+			}
+
+			if !slices.Equal(got, want[mode]) {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want[mode], "\n"))
+			}
+		})
+	}
+}
@@ -1,36 +1,303 @@
 package restc
 
 import (
-	"fmt"
 	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 )
 
+// Schema is a JSON schema of the type
+//
+// Ref contains full qualified type identifier (key of Definitions.Types)
 type Schema struct {
-	Ref string
+	Ref string `json:"$ref,omitempty"`
 
-	Type  string
-	Items *Schema
+	Type     string  `json:"type,omitempty"`
+	Format   string  `json:"format,omitempty"`
+	Nullable bool    `json:"nullable,omitempty"`
+	Items    *Schema `json:"items,omitempty"`
 
-	Required   []string
-	Properties *orderedmap.OrderedMap[string, *Schema]
+	Required             []string                                `json:"required,omitempty"`
+	Properties           *orderedmap.OrderedMap[string, *Schema] `json:"properties,omitempty"`
+	AdditionalProperties *Schema                                 `json:"additionalProperties,omitempty"`
+
+	// AllOf contains schemas of embedded structs
+	AllOf []*Schema `json:"allOf,omitempty"`
+}
+
+var wellKnownSchemas = map[string]Schema{
+	"time Time":                             {Type: "string", Format: "date-time"},
+	"time Duration":                         {Type: "integer", Format: "int64"},
+	"encoding/json RawMessage":              {},
+	"github.com/google/uuid UUID":           {Type: "string", Format: "uuid"},
+	"github.com/gofrs/uuid UUID":            {Type: "string", Format: "uuid"},
+	"github.com/shopspring/decimal Decimal": {Type: "string", Format: "decimal"},
+}
+
+// WellKnownSchema returns schema of the type with custom JSON encoding or nil
+func WellKnownSchema(identifier string) *Schema {
+	if s, ok := wellKnownSchemas[identifier]; ok {
+		return &s
+	}
+	return nil
+}
+
+// PrimitiveSchema returns schema of the builtin type or nil
+func PrimitiveSchema(typeName string) *Schema {
+	switch typeName {
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "string":
+		return &Schema{Type: "string"}
+	case "int", "uint", "uintptr":
+		return &Schema{Type: "integer"}
+	case "int8", "int16", "int32", "uint8", "uint16", "rune", "byte":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int64", "uint32", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	case "any", "comparable":
+		return &Schema{}
+	case "error":
+		// error values are encoded as their dynamic type
+		return &Schema{}
+	case "complex64", "complex128":
+		// encoding/json does not support complex numbers
+		return &Schema{}
+	}
+	return nil
+}
+
+// NewSchemaFromIdentifier returns schema of primitive or well known type, reference for another types,
+// composite identifiers are converted into arrays and objects
+func NewSchemaFromIdentifier(identifier string) *Schema {
+	switch {
+	case IsPointerTypeIdentifier(identifier):
+		s := NewSchemaFromIdentifier(ElemTypeIdentifier(identifier))
+		s.Nullable = true
+		return s
+	case identifier == "[]byte" || identifier == "[]uint8":
+		return &Schema{Type: "string", Format: "byte"}
+	case IsSliceTypeIdentifier(identifier) || IsArrayTypeIdentifier(identifier):
+		return &Schema{
+			Type:  "array",
+			Items: NewSchemaFromIdentifier(ElemTypeIdentifier(identifier)),
+		}
+	case IsMapTypeIdentifier(identifier):
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: NewSchemaFromIdentifier(ElemTypeIdentifier(identifier)),
+		}
+	}
+
+	if s := PrimitiveSchema(identifier); s != nil {
+		return s
+	}
+	if s := WellKnownSchema(identifier); s != nil {
+		return s
+	}
+	return &Schema{Ref: identifier}
 }
 
-func NewSchemaFromNode(node ast.Expr) *Schema {
+// NewSchemaFromNode builds schema from type expression
+//
+// resolveIdentifier is called for named types and must return full qualified type identifier,
+// named types are referenced instead of embedding. isStruct is called for types of embedded fields
+// without pointer and must report whether the named type is a struct, only embedded structs are flattened
+func NewSchemaFromNode(node ast.Expr, resolveIdentifier func(ast.Expr) string, isStruct func(ast.Expr) bool) *Schema {
 	switch n := node.(type) {
 	case *ast.Ident:
-		// TODO: primitive or another type (make ref to another type instead of embedding)
+		if s := PrimitiveSchema(n.Name); s != nil {
+			return s
+		}
+		return NewSchemaFromIdentifier(resolveIdentifier(n))
+	case *ast.SelectorExpr:
+		return NewSchemaFromIdentifier(resolveIdentifier(n))
+	case *ast.StarExpr:
+		s := NewSchemaFromNode(n.X, resolveIdentifier, isStruct)
+		s.Nullable = true
+		return s
 	case *ast.StructType:
-		// TODO: struct
+		fields := make([]StructField, 0, len(n.Fields.List))
 		for _, field := range n.Fields.List {
-			s := NewSchemaFromNode(field.Type)
-			fmt.Println(field, s)
+			tag := ParseJSONTag(field.Tag)
+			fieldSchema := func() *Schema {
+				return NewSchemaFromNode(field.Type, resolveIdentifier, isStruct)
+			}
+
+			// embedded fields are named after their types
+			if len(field.Names) == 0 {
+				typeExpr := field.Type
+				if star, ok := typeExpr.(*ast.StarExpr); ok {
+					typeExpr = star.X
+				}
+
+				name := EmbeddedFieldName(typeExpr)
+				fields = append(fields, StructField{
+					Name:     name,
+					Exported: ast.IsExported(name),
+					Inline:   isStruct(typeExpr),
+					Tag:      tag,
+					Schema:   fieldSchema,
+				})
+				continue
+			}
+
+			for _, fieldName := range field.Names {
+				fields = append(fields, StructField{Name: fieldName.Name, Exported: fieldName.IsExported(), Tag: tag, Schema: fieldSchema})
+			}
 		}
+
+		return NewStructSchema(fields)
 	case *ast.ArrayType:
-		// TODO: array
+		// []byte is encoded as base64 string
+		if el, ok := n.Elt.(*ast.Ident); ok && (el.Name == "byte" || el.Name == "uint8") && n.Len == nil {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{
+			Type:  "array",
+			Items: NewSchemaFromNode(n.Elt, resolveIdentifier, isStruct),
+		}
 	case *ast.MapType:
-		// TODO: map
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: NewSchemaFromNode(n.Value, resolveIdentifier, isStruct),
+		}
 	}
-	return nil
+
+	// interfaces, funcs and channels accept any value
+	return &Schema{}
+}
+
+// EmbeddedFieldName returns name of the embedded field, it is the type name without package and type arguments
+func EmbeddedFieldName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.ParenExpr:
+		return EmbeddedFieldName(e.X)
+	case *ast.IndexExpr:
+		return EmbeddedFieldName(e.X)
+	case *ast.IndexListExpr:
+		return EmbeddedFieldName(e.X)
+	}
+	return ""
+}
+
+// StructField describes struct field for NewStructSchema
+type StructField struct {
+	// Name is the type name for embedded fields
+	Name     string
+	Exported bool
+	// Inline is set for embedded structs and pointers to structs, their fields are promoted by encoding/json
+	Inline bool
+	Tag    JSONTag
+	// Schema builds schema of the field type, it is called only for encoded fields
+	Schema func() *Schema
+}
+
+// NewStructSchema builds object schema of the struct fields encoded by encoding/json, embedded structs without
+// explicit name are flattened into allOf, another embedded types are encoded as fields named after the type
+func NewStructSchema(fields []StructField) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Required:   make([]string, 0),
+		Properties: orderedmap.New[string, *Schema](),
+	}
+
+	embedded := make([]*Schema, 0)
+
+	for _, field := range fields {
+		if field.Tag.Skip {
+			continue
+		}
+
+		// exported fields of embedded structs are encoded even if the struct type is unexported
+		if !field.Exported && !field.Inline {
+			continue
+		}
+
+		if field.Inline && field.Tag.Name == "" {
+			embedded = append(embedded, field.Schema())
+			continue
+		}
+
+		name := field.Name
+		if field.Tag.Name != "" {
+			name = field.Tag.Name
+		}
+
+		fieldSchema := field.Schema()
+		if field.Tag.String && fieldSchema.Type != "" && fieldSchema.Type != "object" && fieldSchema.Type != "array" {
+			fieldSchema = &Schema{Type: "string"}
+		}
+
+		s.Properties.Set(name, fieldSchema)
+
+		// nil pointers are encoded as null, only omitted fields are not required
+		if !field.Tag.OmitEmpty {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	if len(embedded) > 0 {
+		return &Schema{AllOf: append(embedded, s)}
+	}
+
+	return s
+}
+
+// JSONTag is a parsed encoding/json struct field tag
+type JSONTag struct {
+	Name      string
+	Skip      bool
+	OmitEmpty bool
+	String    bool
+}
+
+func ParseJSONTag(tag *ast.BasicLit) JSONTag {
+	if tag == nil {
+		return JSONTag{}
+	}
+
+	value, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return JSONTag{}
+	}
+
+	return ParseJSONStructTag(value)
+}
+
+// ParseJSONStructTag parses json key of the unquoted struct tag
+func ParseJSONStructTag(tag string) JSONTag {
+	jsonTag, ok := reflect.StructTag(tag).Lookup("json")
+	if !ok {
+		return JSONTag{}
+	}
+
+	if jsonTag == "-" {
+		return JSONTag{Skip: true}
+	}
+
+	parts := strings.Split(jsonTag, ",")
+	t := JSONTag{Name: parts[0]}
+
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty", "omitzero":
+			t.OmitEmpty = true
+		case "string":
+			t.String = true
+		}
+	}
+
+	return t
 }
@@ -0,0 +1,125 @@
+package restc
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"testing"
+)
+
+func TestParseJSONStructTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want JSONTag
+	}{
+		{tag: ``, want: JSONTag{}},
+		{tag: `yaml:"name"`, want: JSONTag{}},
+		{tag: `json:"name"`, want: JSONTag{Name: "name"}},
+		{tag: `json:"-"`, want: JSONTag{Skip: true}},
+		{tag: `json:"-,"`, want: JSONTag{Name: "-"}},
+		{tag: `json:",omitempty"`, want: JSONTag{OmitEmpty: true}},
+		{tag: `json:"name,omitempty"`, want: JSONTag{Name: "name", OmitEmpty: true}},
+		{tag: `json:"name,omitzero"`, want: JSONTag{Name: "name", OmitEmpty: true}},
+		{tag: `json:"count,string"`, want: JSONTag{Name: "count", String: true}},
+		{tag: `json:"count,omitempty,string" yaml:"c"`, want: JSONTag{Name: "count", OmitEmpty: true, String: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := ParseJSONStructTag(tt.tag); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSchemaFromNode(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{name: "primitive", expr: "int64", want: `{"type":"integer","format":"int64"}`},
+		{name: "error", expr: "error", want: `{}`},
+		{name: "named", expr: "Task", want: `{"$ref":"example.com/app Task"}`},
+		{name: "well known", expr: "time.Time", want: `{"type":"string","format":"date-time"}`},
+		{name: "pointer", expr: "*string", want: `{"type":"string","nullable":true}`},
+		{name: "bytes", expr: "[]byte", want: `{"type":"string","format":"byte"}`},
+		{name: "array", expr: "[2]byte", want: `{"type":"array","items":{"type":"integer","format":"int32"}}`},
+		{name: "slice", expr: "[]Task", want: `{"type":"array","items":{"$ref":"example.com/app Task"}}`},
+		{name: "map", expr: "map[string]*Task", want: `{"type":"object","additionalProperties":{"$ref":"example.com/app Task","nullable":true}}`},
+		{name: "interface", expr: "interface{ Close() error }", want: `{}`},
+		{
+			name: "struct fields",
+			expr: "struct { ID string; Name, Description string; internal int }",
+			want: `{"type":"object","required":["ID","Name","Description"],"properties":{"ID":{"type":"string"},"Name":{"type":"string"},"Description":{"type":"string"}}}`,
+		},
+		{
+			name: "json tags",
+			expr: "struct { ID string `json:\"id\"`; Secret string `json:\"-\"`; Count int `json:\"count,string\"`; Tags []string `json:\"tags,omitempty\"` }",
+			want: `{"type":"object","required":["id","count"],"properties":{"id":{"type":"string"},"count":{"type":"string"},"tags":{"type":"array","items":{"type":"string"}}}}`,
+		},
+		{
+			name: "pointers are required unless omitted",
+			expr: "struct { Parent *Task `json:\"parent\"`; Label *string `json:\"label,omitempty\"` }",
+			want: `{"type":"object","required":["parent"],"properties":{"parent":{"$ref":"example.com/app Task","nullable":true},"label":{"type":"string","nullable":true}}}`,
+		},
+		{
+			name: "embedded",
+			expr: "struct { Base; Named `json:\"named\"`; ID string `json:\"id\"` }",
+			want: `{"allOf":[{"$ref":"example.com/app Base"},{"type":"object","required":["named","id"],"properties":{"named":{"$ref":"example.com/app Named"},"id":{"type":"string"}}}]}`,
+		},
+		{
+			name: "embedded pointer and unexported structs",
+			expr: "struct { *Base; base }",
+			want: `{"allOf":[{"$ref":"example.com/app Base","nullable":true},{"$ref":"example.com/app base"},{"type":"object","properties":{}}]}`,
+		},
+		{
+			name: "embedded non struct types",
+			expr: "struct { Tags; *Labels; status; Status `json:\"status,omitempty\"` }",
+			want: `{"type":"object","required":["Tags","Labels"],"properties":{"Tags":{"$ref":"example.com/app Tags"},"Labels":{"$ref":"example.com/app Labels","nullable":true},"status":{"$ref":"example.com/app Status"}}}`,
+		},
+		{
+			name: "embedded qualified type",
+			expr: "struct { dto.Tags }",
+			want: `{"type":"object","required":["Tags"],"properties":{"Tags":{"$ref":"dto Tags"}}}`,
+		},
+	}
+
+	resolve := func(expr ast.Expr) string {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return "example.com/app " + e.Name
+		case *ast.SelectorExpr:
+			return e.X.(*ast.Ident).Name + " " + e.Sel.Name
+		}
+		t.Fatalf("unexpected expression %T", expr)
+		return ""
+	}
+
+	// embedded types are structs unless they are named after non struct types
+	isStruct := func(expr ast.Expr) bool {
+		switch EmbeddedFieldName(expr) {
+		case "Tags", "Labels", "Status", "status":
+			return false
+		}
+		return true
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(NewSchemaFromNode(expr, resolve, isStruct))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
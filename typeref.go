@@ -0,0 +1,69 @@
+package restc
+
+import (
+	"strconv"
+	"strings"
+)
+
+type TypeKind string
+
+const (
+	TypeKindBuiltin TypeKind = "builtin"
+	TypeKindNamed   TypeKind = "named"
+	TypeKindPointer TypeKind = "pointer"
+	TypeKindSlice   TypeKind = "slice"
+	TypeKindArray   TypeKind = "array"
+	TypeKindMap     TypeKind = "map"
+)
+
+// TypeRef is the structured form of the type identifier for plugins which do not parse identifiers,
+// Package is set for named types, Elem for composite types and Key for maps
+type TypeRef struct {
+	Kind    TypeKind `json:"kind"`
+	Package string   `json:"package,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Len     int      `json:"len,omitempty"`
+	Key     *TypeRef `json:"key,omitempty"`
+	Elem    *TypeRef `json:"elem,omitempty"`
+}
+
+// NewTypeRef parses type identifier
+func NewTypeRef(identifier string) *TypeRef {
+	switch {
+	case IsPointerTypeIdentifier(identifier):
+		return &TypeRef{Kind: TypeKindPointer, Elem: NewTypeRef(ElemTypeIdentifier(identifier))}
+	case IsSliceTypeIdentifier(identifier):
+		return &TypeRef{Kind: TypeKindSlice, Elem: NewTypeRef(ElemTypeIdentifier(identifier))}
+	case IsArrayTypeIdentifier(identifier):
+		n, _ := strconv.Atoi(ArrayLenTypeIdentifier(identifier))
+		return &TypeRef{Kind: TypeKindArray, Len: n, Elem: NewTypeRef(ElemTypeIdentifier(identifier))}
+	case IsMapTypeIdentifier(identifier):
+		return &TypeRef{
+			Kind: TypeKindMap,
+			Key:  NewTypeRef(MapKeyTypeIdentifier(identifier)),
+			Elem: NewTypeRef(ElemTypeIdentifier(identifier)),
+		}
+	}
+
+	if packagePath, name, ok := strings.Cut(identifier, " "); ok {
+		return &TypeRef{Kind: TypeKindNamed, Package: packagePath, Name: name}
+	}
+	return &TypeRef{Kind: TypeKindBuiltin, Name: identifier}
+}
+
+// Identifier formats type reference back into type identifier
+func (t *TypeRef) Identifier() string {
+	switch t.Kind {
+	case TypeKindPointer:
+		return "*" + t.Elem.Identifier()
+	case TypeKindSlice:
+		return "[]" + t.Elem.Identifier()
+	case TypeKindArray:
+		return "[" + strconv.Itoa(t.Len) + "]" + t.Elem.Identifier()
+	case TypeKindMap:
+		return "map[" + t.Key.Identifier() + "]" + t.Elem.Identifier()
+	case TypeKindNamed:
+		return t.Package + " " + t.Name
+	}
+	return t.Name
+}
@@ -0,0 +1,51 @@
+package restc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTypeRef(t *testing.T) {
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{identifier: "string", want: `{"kind":"builtin","name":"string"}`},
+		{identifier: "error", want: `{"kind":"builtin","name":"error"}`},
+		{identifier: "time Time", want: `{"kind":"named","package":"time","name":"Time"}`},
+		{identifier: "*example.com/app Task", want: `{"kind":"pointer","elem":{"kind":"named","package":"example.com/app","name":"Task"}}`},
+		{identifier: "[]*int", want: `{"kind":"slice","elem":{"kind":"pointer","elem":{"kind":"builtin","name":"int"}}}`},
+		{identifier: "[16]byte", want: `{"kind":"array","len":16,"elem":{"kind":"builtin","name":"byte"}}`},
+		{identifier: "[0]int", want: `{"kind":"array","elem":{"kind":"builtin","name":"int"}}`},
+		{
+			identifier: "map[example.com/app Key][]example.com/app/dto Item",
+			want:       `{"kind":"map","key":{"kind":"named","package":"example.com/app","name":"Key"},"elem":{"kind":"slice","elem":{"kind":"named","package":"example.com/app/dto","name":"Item"}}}`,
+		},
+		{
+			identifier: "map[[2]string]map[string]bool",
+			want:       `{"kind":"map","key":{"kind":"array","len":2,"elem":{"kind":"builtin","name":"string"}},"elem":{"kind":"map","key":{"kind":"builtin","name":"string"},"elem":{"kind":"builtin","name":"bool"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			ref := NewTypeRef(tt.identifier)
+
+			got, err := json.Marshal(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+
+			var decoded TypeRef
+			if err := json.Unmarshal(got, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if identifier := decoded.Identifier(); identifier != tt.identifier {
+				t.Errorf("Identifier = %q", identifier)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package restc
 
 import "slices"
 
+// primitives are predeclared types, they are not qualified with package path
 var primitives []string = []string{
 	"bool",
 	"string",
@@ -22,8 +23,29 @@ var primitives []string = []string{
 	"float64",
 	"complex64",
 	"complex128",
+	"error",
+	"any",
+	"comparable",
 }
 
 func IsPrimitive(typeIdentifier string) bool {
 	return slices.Contains(primitives, typeIdentifier)
 }
+
+// BasicTypeName returns name of the predeclared basic type with byte and rune aliases resolved,
+// empty string is returned for another types
+func BasicTypeName(typeIdentifier string) string {
+	switch typeIdentifier {
+	case "byte":
+		return "uint8"
+	case "rune":
+		return "int32"
+	case "error", "any", "comparable":
+		return ""
+	}
+
+	if IsPrimitive(typeIdentifier) {
+		return typeIdentifier
+	}
+	return ""
+}